@@ -2,20 +2,45 @@ package main
 
 import (
 	"bufio" // Added for reading initial lines
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/hpcloud/tail"
 )
 
+// slogger is the dashboard's structured logger: it writes one JSON line
+// per event (level, msg, and whatever fields .With()/the call site adds)
+// to stdout, replacing the ad-hoc log.Printf calls in controlHandler and
+// logsHandler so operators can correlate one action - e.g. a
+// put-conflict-key-nodeA/nodeB pair - by request_id instead of grepping
+// free-text lines.
+var slogger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+// newRequestID mints a short correlation ID for one dashboard action.
+// It's attached to the slogger entries for that action and, for the key
+// PUT/GET actions that shell out to curl against the backend (force-sync
+// sends none), forwarded as the
+// X-Request-Id header so the backend's own structured logs carry the same
+// ID - letting an operator pull one logical operation's trail out of
+// every node's log file with a single request_id filter.
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // Data structure to pass to the HTML template
 type PageData struct {
 	Nodes  []NodeStatus
@@ -111,24 +136,43 @@ func controlHandler(w http.ResponseWriter, r *http.Request) {
 	nodeID := r.FormValue("node_id")
 	testKey := r.FormValue("test_key") // For specific test keys (used by GET)
 
-	log.Printf("Control action received: '%s' for node: '%s', key: '%s'", action, nodeID, testKey)
+	reqID := newRequestID()
+	rlog := slogger.With("request_id", reqID, "action", action, "node_id", nodeID)
+	reqIDHeader := fmt.Sprintf("X-Request-Id: %s", reqID)
+
+	rlog.Info("control action received", "key", testKey)
 	var cmd *exec.Cmd
-	projectDir := "../backend/" // Relative path to your project folder
 
 	switch action {
 	case "stop-node":
-		cmd = exec.Command("pkill", "-f", nodeID)
+		if err := supervisor.Stop(nodeID, true); err != nil {
+			rlog.Error("supervisor stop failed", "error", err.Error())
+			http.Error(w, fmt.Sprintf("Failed to stop node: %v", err), http.StatusInternalServerError)
+			return
+		}
+		rlog.Info("stopped node via supervisor")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
 	case "force-sync":
 		cmd = exec.Command("curl", "-s", "-X", "POST", "http://localhost:5000/admin/sync")
 	case "restart-all":
-		cmd = exec.Command(filepath.Join(projectDir, "run_cluster.sh"))
-		cmd.Dir = projectDir
+		go func() {
+			for _, spec := range supervisorNodes {
+				if err := supervisor.Restart(spec.ID); err != nil {
+					rlog.Error("supervisor restart failed", "node_id", spec.ID, "error", err.Error())
+				}
+			}
+		}()
+		rlog.Info("restart-all dispatched via supervisor")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("Restarting all nodes via supervisor. Check logs for status."))
+		return
 	case "put-value": // This is the auto-generated PUT
 		key := fmt.Sprintf("test-key-%d", time.Now().Unix())
 		value := fmt.Sprintf("test-value-%d", time.Now().Unix())
-		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json",
+		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json", "-H", reqIDHeader,
 			"-d", fmt.Sprintf("{\"value\":\"%s\"}", value), "http://localhost:5000/kv/"+key)
-		log.Printf("Putting auto-generated key: %s, value: %s", key, value)
+		rlog.Info("putting auto-generated key", "key", key, "value", value)
 
 	case "put-custom-value": // This handles user-defined PUT
 		customKey := r.FormValue("custom_key")
@@ -137,26 +181,29 @@ func controlHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Custom PUT requires both a key and a value.", http.StatusBadRequest)
 			return
 		}
-		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json",
+		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json", "-H", reqIDHeader,
 			"-d", fmt.Sprintf("{\"value\":\"%s\"}", customValue), "http://localhost:5000/kv/"+customKey)
-		log.Printf("Putting custom key: %s, value: %s", customKey, customValue)
+		rlog.Info("putting custom key", "key", customKey, "value", customValue)
 
 	case "get-value": // This handles GET for any provided key
 		if testKey == "" {
-			log.Println("GET action requires a 'test_key' parameter. Cannot perform GET.")
+			rlog.Warn("get action requires a test_key parameter")
 			http.Error(w, "GET action requires a key", http.StatusBadRequest)
 			return
 		}
-		cmd = exec.Command("curl", "-s", "http://localhost:5000/kv/"+testKey)
-		log.Printf("Getting key: %s", testKey)
+		cmd = exec.Command("curl", "-s", "-H", reqIDHeader, "http://localhost:5000/kv/"+testKey)
+		rlog.Info("getting key", "key", testKey)
 
 	case "stop-nodeC-nodeD":
 		go func() {
-			log.Println("Executing: pkill -f \"nodeC\" || true")
-			exec.Command("pkill", "-f", "nodeC").Run()
-			log.Println("Executing: pkill -f \"nodeD\" || true")
-			exec.Command("pkill", "-f", "nodeD").Run()
-			log.Println("Nodes C & D stop commands sent. Waiting 5s for gossip to update.")
+			for _, id := range []string{"nodeC", "nodeD"} {
+				if err := supervisor.Stop(id, true); err != nil {
+					rlog.Warn("supervisor stop failed", "node_id", id, "error", err.Error())
+				} else {
+					rlog.Info("stopped node via supervisor", "node_id", id)
+				}
+			}
+			rlog.Info("nodeC & nodeD stop commands sent, waiting for gossip to update")
 			time.Sleep(5 * time.Second)
 		}()
 		w.WriteHeader(http.StatusOK)
@@ -164,37 +211,37 @@ func controlHandler(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case "put-fault-key-nodeA":
-		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json",
+		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json", "-H", reqIDHeader,
 			"-d", fmt.Sprintf("{\"value\":\"Fault tolerant\"}"), "http://localhost:5000/kv/"+faultKey)
-		log.Printf("Putting fault key: %s on nodeA", faultKey)
+		rlog.Info("putting fault key on nodeA", "key", faultKey)
 	case "get-fault-key-nodeA":
-		cmd = exec.Command("curl", "-s", "http://localhost:5000/kv/"+faultKey)
-		log.Printf("Getting fault key: %s from nodeA", faultKey)
+		cmd = exec.Command("curl", "-s", "-H", reqIDHeader, "http://localhost:5000/kv/"+faultKey)
+		rlog.Info("getting fault key from nodeA", "key", faultKey)
 	case "put-hint-key-nodeA":
-		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json",
+		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json", "-H", reqIDHeader,
 			"-d", fmt.Sprintf("{\"value\":\"Hint test value\"}"), "http://localhost:5000/kv/"+hintKey)
-		log.Printf("Putting hint key: %s on nodeA", hintKey)
+		rlog.Info("putting hint key on nodeA", "key", hintKey)
 	case "get-fault-key-nodeC":
-		cmd = exec.Command("curl", "-s", "http://localhost:5002/kv/"+faultKey)
-		log.Printf("Getting fault key: %s from nodeC", faultKey)
+		cmd = exec.Command("curl", "-s", "-H", reqIDHeader, "http://localhost:5002/kv/"+faultKey)
+		rlog.Info("getting fault key from nodeC", "key", faultKey)
 	case "force-replicate-fault-key-nodeC":
-		cmd = exec.Command("curl", "-s", "-X", "POST", "-H", "Content-Type: application/json",
+		cmd = exec.Command("curl", "-s", "-X", "POST", "-H", "Content-Type: application/json", "-H", reqIDHeader,
 			"-d", fmt.Sprintf("{\"node\":\"nodeC\", \"key\":\"%s\"}", faultKey), "http://localhost:5000/admin/sync")
-		log.Printf("Attempting to force replicate %s to nodeC via admin/sync", faultKey)
+		rlog.Info("forcing replication of fault key to nodeC via admin/sync", "key", faultKey)
 	case "initial-put-conflict-key-nodeA":
-		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json",
+		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json", "-H", reqIDHeader,
 			"-d", fmt.Sprintf("{\"value\":\"Version A\"}"), "http://localhost:5000/kv/"+conflictKey)
-		log.Printf("Initial PUT for conflict key: %s on nodeA", conflictKey)
+		rlog.Info("initial PUT for conflict key on nodeA", "key", conflictKey)
 	case "put-conflict-key-nodeA":
-		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json",
+		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json", "-H", reqIDHeader,
 			"-d", fmt.Sprintf("{\"value\":\"Version A-updated\"}"), "http://localhost:5000/kv/"+conflictKey)
-		log.Printf("PUT for conflict key: %s on nodeA (A-updated)", conflictKey)
+		rlog.Info("PUT for conflict key on nodeA (A-updated)", "key", conflictKey)
 	case "put-conflict-key-nodeB":
-		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json",
+		cmd = exec.Command("curl", "-s", "-X", "PUT", "-H", "Content-Type: application/json", "-H", reqIDHeader,
 			"-d", fmt.Sprintf("{\"value\":\"Version B-updated\"}"), "http://localhost:5001/kv/"+conflictKey)
-		log.Printf("PUT for conflict key: %s on nodeB (B-updated)", conflictKey)
+		rlog.Info("PUT for conflict key on nodeB (B-updated)", "key", conflictKey)
 	case "get-conflict-key-all-nodes":
-		log.Printf("Fetching conflict key '%s' from all nodes for verification...", conflictKey)
+		rlog.Info("fetching conflict key from all nodes for verification", "key", conflictKey)
 		go func() {
 			nodes := []struct {
 				ID   string
@@ -203,18 +250,20 @@ func controlHandler(w http.ResponseWriter, r *http.Request) {
 				{"nodeA", 5000}, {"nodeB", 5001}, {"nodeC", 5002}, {"nodeD", 5003},
 			}
 			for _, n := range nodes {
-				resp, err := http.Get(fmt.Sprintf("http://localhost:%d/kv/%s", n.Port, conflictKey))
+				req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/kv/%s", n.Port, conflictKey), nil)
+				req.Header.Set("X-Request-Id", reqID)
+				resp, err := http.DefaultClient.Do(req)
 				if err != nil {
-					log.Printf("Error fetching %s from %s: %v", conflictKey, n.ID, err)
+					rlog.Error("error fetching conflict key", "key", conflictKey, "peer_node", n.ID, "error", err.Error())
 					continue
 				}
 				defer resp.Body.Close()
 				var bodyContent map[string]interface{}
 				if err := json.NewDecoder(resp.Body).Decode(&bodyContent); err != nil {
-					log.Printf("Response for %s from %s: Status=%s, Body (non-JSON)=%s", conflictKey, n.ID, resp.Status, "Could not decode JSON")
+					rlog.Info("response for conflict key (non-JSON body)", "key", conflictKey, "peer_node", n.ID, "status", resp.Status)
 				} else {
 					bodyBytes, _ := json.Marshal(bodyContent)
-					log.Printf("Response for %s from %s: Status=%s, Body=%s", conflictKey, n.ID, resp.Status, string(bodyBytes))
+					rlog.Info("response for conflict key", "key", conflictKey, "peer_node", n.ID, "status", resp.Status, "body", string(bodyBytes))
 				}
 			}
 		}()
@@ -222,36 +271,37 @@ func controlHandler(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Fetching results from all nodes. Check logs."))
 		return
 	default:
-		log.Println("Unknown action:", action)
+		rlog.Warn("unknown action")
 		http.Error(w, "Unknown action", http.StatusBadRequest)
 		return
 	}
 
 	if cmd != nil {
-		if action == "restart-all" {
-			err := cmd.Start()
-			if err != nil {
-				log.Printf("Command execution error: %v", err)
-				http.Error(w, fmt.Sprintf("Failed to execute command: %v", err), http.StatusInternalServerError)
-				return
-			}
-			log.Printf("Started command: %s", cmd.String())
-			time.Sleep(1 * time.Second)
-		} else {
-			output, err := cmd.CombinedOutput()
-			if err != nil {
-				log.Printf("Command execution error: %v, Output: %s", err, string(output))
-				if !(action == "stop-node" || action == "stop-nodeC-nodeD") {
-					http.Error(w, fmt.Sprintf("Command failed: %v, Output: %s", err, string(output)), http.StatusInternalServerError)
-				}
-				return
-			}
-			log.Printf("Command output: %s", string(output))
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			rlog.Error("command execution error", "error", err.Error(), "output", string(output))
+			http.Error(w, fmt.Sprintf("Command failed: %v, Output: %s", err, string(output)), http.StatusInternalServerError)
+			return
 		}
+		rlog.Info("command output", "output", string(output))
 	}
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// supervisorStatusHandler reports the NodeSupervisor's view of every
+// known node's process state, so the dashboard can show true
+// supervisor-tracked liveness (pid, restarts, last exit) alongside the
+// gossip-derived status getClusterStatus already renders.
+func supervisorStatusHandler(w http.ResponseWriter, r *http.Request) {
+	js, err := json.Marshal(supervisor.Status())
+	if err != nil {
+		http.Error(w, "Failed to marshal supervisor status", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
 // testKeysHandler returns the dynamically generated test keys
 func testKeysHandler(w http.ResponseWriter, r *http.Request) {
 	keys := map[string]string{
@@ -273,6 +323,44 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
+// logLineMatches reports whether a log line passes the level/request_id/key
+// filters a logsHandler caller supplied via query params. With no filters
+// set, every line passes (keeps plain-text lines written before this
+// request, or by any call site structLog hasn't reached yet, visible as
+// before). Once a filter is set, a line must parse as one of the backend's
+// structured JSON entries and match - free-text lines can't satisfy a
+// structured filter, so they're dropped rather than guessed at.
+func logLineMatches(line, levelFilter, requestIDFilter, keyFilter string) bool {
+	if levelFilter == "" && requestIDFilter == "" && keyFilter == "" {
+		return true
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return false
+	}
+
+	if levelFilter != "" {
+		level, _ := entry["level"].(string)
+		if !strings.EqualFold(level, levelFilter) {
+			return false
+		}
+	}
+	if requestIDFilter != "" {
+		reqID, _ := entry["request_id"].(string)
+		if reqID != requestIDFilter {
+			return false
+		}
+	}
+	if keyFilter != "" {
+		key, _ := entry["key"].(string)
+		if key != keyFilter {
+			return false
+		}
+	}
+	return true
+}
+
 // logsHandler streams log files
 func logsHandler(w http.ResponseWriter, r *http.Request) {
 	nodeID := r.URL.Query().Get("node")
@@ -280,9 +368,15 @@ func logsHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Missing 'node' parameter", http.StatusBadRequest)
 		return
 	}
+	levelFilter := r.URL.Query().Get("level")
+	requestIDFilter := r.URL.Query().Get("request_id")
+	keyFilter := r.URL.Query().Get("key")
+
+	llog := slogger.With("node_id", nodeID, "level_filter", levelFilter, "request_id_filter", requestIDFilter, "key_filter", keyFilter)
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade failed: %v", err)
+		llog.Error("websocket upgrade failed", "error", err.Error())
 		return
 	}
 	defer conn.Close()
@@ -293,7 +387,7 @@ func logsHandler(w http.ResponseWriter, r *http.Request) {
 	// Read initial lines using standard Go file I/O
 	f, err := os.Open(logFile)
 	if err != nil {
-		log.Printf("Error opening log file %s for initial read: %v", logFile, err)
+		llog.Error("error opening log file for initial read", "error", err.Error())
 		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Error: Could not open log file %s. Make sure node is running and logs are being written.", logFile)))
 		return
 	}
@@ -301,32 +395,39 @@ func logsHandler(w http.ResponseWriter, r *http.Request) {
 
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
-		if err := conn.WriteMessage(websocket.TextMessage, []byte(scanner.Text())); err != nil {
-			log.Printf("Error sending initial lines to websocket: %v", err)
+		line := scanner.Text()
+		if !logLineMatches(line, levelFilter, requestIDFilter, keyFilter) {
+			continue
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(line)); err != nil {
+			llog.Error("error sending initial lines to websocket", "error", err.Error())
 			return // Client disconnected during initial send
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		log.Printf("Error reading initial lines from log file: %v", err)
+		llog.Error("error reading initial lines from log file", "error", err.Error())
 	}
 	// --- FIX END ---
 
 	// Then, start tailing the file for new lines
 	t, err := tail.TailFile(logFile, tail.Config{Follow: true, ReOpen: true, MustExist: true, Poll: true})
 	if err != nil {
-		log.Printf("Error tailing log file %s: %v", logFile, err)
+		llog.Error("error tailing log file", "error", err.Error())
 		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Error: Could not tail new log lines from file %s.", logFile)))
 		return
 	}
 	defer t.Cleanup() // Ensure tail stops when done
 
 	for line := range t.Lines {
+		if !logLineMatches(line.Text, levelFilter, requestIDFilter, keyFilter) {
+			continue
+		}
 		if err := conn.WriteMessage(websocket.TextMessage, []byte(line.Text)); err != nil {
-			log.Printf("Error writing to websocket (broken pipe likely, client disconnected): %v", err)
+			llog.Error("error writing to websocket, client likely disconnected", "error", err.Error())
 			break // Client disconnected or connection broke
 		}
 	}
-	log.Printf("Log streaming for %s stopped.", nodeID)
+	llog.Info("log streaming stopped")
 }
 
 func main() {
@@ -334,6 +435,7 @@ func main() {
 	http.HandleFunc("/control", controlHandler)
 	http.HandleFunc("/ws/logs", logsHandler)
 	http.HandleFunc("/admin/test-keys", testKeysHandler) // Register the new handler
+	http.HandleFunc("/admin/supervisor", supervisorStatusHandler)
 
 	log.Println("Starting Go-only UI on http://localhost:8080")
 	if err := http.ListenAndServe(":8080", nil); err != nil {
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backendDir is where a supervised node's `go run .` is launched from, and
+// the root logsHandler already reads <nodeID>.txt out of.
+const backendDir = "../backend/"
+
+// gracefulShutdownTimeout bounds how long Stop(nodeID, true) waits for a
+// SIGTERM'd node to exit on its own before escalating to SIGKILL.
+const gracefulShutdownTimeout = 5 * time.Second
+
+// maxAutoRestarts caps how many times NodeSupervisor will bring a node
+// back up after an unexpected exit before giving up and leaving it down -
+// a crash loop shouldn't eat the host's CPU/fds forever.
+const maxAutoRestarts = 5
+
+// autoRestartBaseDelay/autoRestartMaxDelay bound the exponential backoff
+// between auto-restart attempts: 0.5s, 1s, 2s, 4s, ... capped at 30s.
+const (
+	autoRestartBaseDelay = 500 * time.Millisecond
+	autoRestartMaxDelay  = 30 * time.Second
+)
+
+// nodeSpec is the static launch configuration for one supervised backend
+// node: its CLI-convention node ID and the port main() should listen on.
+// This mirrors the nodeA/B/C/D -> 5000/5001/5002/5003 mapping controlHandler's
+// curl-based test actions already assume.
+type nodeSpec struct {
+	ID   string
+	Port int
+}
+
+var supervisorNodes = []nodeSpec{
+	{"nodeA", 5000},
+	{"nodeB", 5001},
+	{"nodeC", 5002},
+	{"nodeD", 5003},
+}
+
+func nodeSpecFor(nodeID string) (nodeSpec, bool) {
+	for _, spec := range supervisorNodes {
+		if spec.ID == nodeID {
+			return spec, true
+		}
+	}
+	return nodeSpec{}, false
+}
+
+// peerArgsFor builds the `node:port` positional args main() expects for
+// every node other than nodeID, using the same backward-compatible CLI
+// form main() falls back to when no -config is given.
+func peerArgsFor(nodeID string) []string {
+	var peers []string
+	for _, spec := range supervisorNodes {
+		if spec.ID == nodeID {
+			continue
+		}
+		peers = append(peers, fmt.Sprintf("%s:%d", spec.ID, spec.Port))
+	}
+	return peers
+}
+
+// supervisedProcess is one node's tracked child process plus enough state
+// to decide, once it exits, whether that was an intentional Stop (no
+// auto-restart) or a crash (auto-restart with backoff, up to the budget).
+type supervisedProcess struct {
+	mu         sync.Mutex
+	cmd        *exec.Cmd
+	logFile    *os.File
+	exited     chan struct{}
+	startedAt  time.Time
+	stopping   bool
+	restarts   int
+	lastExit   error
+	lastExitAt time.Time
+}
+
+// NodeSupervisor launches and tracks backend node processes in place of
+// controlHandler's old pkill/run_cluster.sh shell-outs. One NodeSupervisor
+// is shared by the whole dashboard process.
+type NodeSupervisor struct {
+	mu    sync.Mutex
+	procs map[string]*supervisedProcess
+}
+
+func NewNodeSupervisor() *NodeSupervisor {
+	return &NodeSupervisor{procs: make(map[string]*supervisedProcess)}
+}
+
+var supervisor = NewNodeSupervisor()
+
+// SupervisorNodeStatus is the JSON shape /admin/supervisor reports per node.
+type SupervisorNodeStatus struct {
+	NodeID     string `json:"node_id"`
+	Running    bool   `json:"running"`
+	Pid        int    `json:"pid,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	Restarts   int    `json:"restarts"`
+	LastExit   string `json:"last_exit,omitempty"`
+	LastExitAt string `json:"last_exit_at,omitempty"`
+}
+
+// isRunning reports whether proc's child process is still the one being
+// waited on, i.e. its exited channel hasn't been closed yet. Caller must
+// hold proc.mu.
+func (proc *supervisedProcess) isRunning() bool {
+	if proc.cmd == nil || proc.exited == nil {
+		return false
+	}
+	select {
+	case <-proc.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// Start launches nodeID's backend process if it isn't already running,
+// streaming its stdout/stderr into ../backend/logs/<nodeID>.txt - the same
+// file logsHandler tails - and arms auto-restart for its next exit.
+func (s *NodeSupervisor) Start(nodeID string) error {
+	spec, ok := nodeSpecFor(nodeID)
+	if !ok {
+		return fmt.Errorf("unknown node %q", nodeID)
+	}
+
+	s.mu.Lock()
+	proc, exists := s.procs[nodeID]
+	if !exists {
+		proc = &supervisedProcess{}
+		s.procs[nodeID] = proc
+	}
+	s.mu.Unlock()
+
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+	if proc.isRunning() {
+		return fmt.Errorf("node %s is already running (pid %d)", nodeID, proc.cmd.Process.Pid)
+	}
+
+	logPath := backendDir + "logs/" + nodeID + ".txt"
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening log file for %s: %w", nodeID, err)
+	}
+
+	args := append([]string{"run", ".", spec.ID, strconv.Itoa(spec.Port)}, peerArgsFor(spec.ID)...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = backendDir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return fmt.Errorf("starting %s: %w", nodeID, err)
+	}
+
+	proc.cmd = cmd
+	proc.logFile = logFile
+	proc.exited = make(chan struct{})
+	proc.startedAt = time.Now()
+	proc.stopping = false
+
+	slogger.Info("supervisor started node", "node_id", nodeID, "pid", cmd.Process.Pid, "port", spec.Port)
+	go s.watch(nodeID, proc)
+	return nil
+}
+
+// watch waits for proc's child to exit and, unless Stop marked it as an
+// intentional shutdown or the restart budget is spent, relaunches it after
+// an exponential backoff delay.
+func (s *NodeSupervisor) watch(nodeID string, proc *supervisedProcess) {
+	cmd := proc.cmd
+	err := cmd.Wait()
+
+	proc.mu.Lock()
+	proc.lastExit = err
+	proc.lastExitAt = time.Now()
+	stopping := proc.stopping
+	restarts := proc.restarts
+	close(proc.exited)
+	proc.logFile.Close()
+	proc.mu.Unlock()
+
+	if stopping {
+		slogger.Info("supervisor observed intentional stop", "node_id", nodeID)
+		return
+	}
+	if restarts >= maxAutoRestarts {
+		slogger.Error("supervisor giving up on node, auto-restart budget exhausted", "node_id", nodeID, "restarts", restarts)
+		return
+	}
+
+	delay := autoRestartBaseDelay << restarts
+	if delay > autoRestartMaxDelay {
+		delay = autoRestartMaxDelay
+	}
+	slogger.Warn("node exited unexpectedly, scheduling auto-restart", "node_id", nodeID, "error", fmt.Sprint(err), "attempt", restarts+1, "delay", delay.String())
+	time.Sleep(delay)
+
+	proc.mu.Lock()
+	proc.restarts++
+	proc.mu.Unlock()
+
+	if err := s.Start(nodeID); err != nil {
+		slogger.Error("auto-restart failed", "node_id", nodeID, "error", err.Error())
+	}
+}
+
+// Stop halts nodeID's process and marks it as intentionally stopped so
+// watch doesn't auto-restart it. graceful=true sends SIGTERM and waits up
+// to gracefulShutdownTimeout before escalating to SIGKILL; graceful=false
+// kills immediately.
+func (s *NodeSupervisor) Stop(nodeID string, graceful bool) error {
+	s.mu.Lock()
+	proc, exists := s.procs[nodeID]
+	s.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("node %s has never been started by the supervisor", nodeID)
+	}
+
+	proc.mu.Lock()
+	if !proc.isRunning() {
+		proc.mu.Unlock()
+		return fmt.Errorf("node %s is not running", nodeID)
+	}
+	proc.stopping = true
+	cmd := proc.cmd
+	exited := proc.exited
+	proc.mu.Unlock()
+
+	if !graceful {
+		return cmd.Process.Kill()
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		return cmd.Process.Kill()
+	}
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(gracefulShutdownTimeout):
+		slogger.Warn("graceful shutdown timed out, sending SIGKILL", "node_id", nodeID)
+		return cmd.Process.Kill()
+	}
+}
+
+// Restart stops nodeID (gracefully, if running) and starts it again.
+func (s *NodeSupervisor) Restart(nodeID string) error {
+	s.mu.Lock()
+	_, exists := s.procs[nodeID]
+	s.mu.Unlock()
+	if exists {
+		if err := s.Stop(nodeID, true); err != nil {
+			slogger.Warn("restart: stop failed, starting anyway", "node_id", nodeID, "error", err.Error())
+		}
+	}
+	return s.Start(nodeID)
+}
+
+// Status reports the supervisor's view of every known node, regardless of
+// whether it's ever been started - gossip-derived liveness in
+// getClusterStatus reflects what the cluster believes; this reflects what
+// this dashboard process actually launched and is watching.
+func (s *NodeSupervisor) Status() map[string]SupervisorNodeStatus {
+	result := make(map[string]SupervisorNodeStatus, len(supervisorNodes))
+	for _, spec := range supervisorNodes {
+		status := SupervisorNodeStatus{NodeID: spec.ID}
+
+		s.mu.Lock()
+		proc, exists := s.procs[spec.ID]
+		s.mu.Unlock()
+		if exists {
+			proc.mu.Lock()
+			status.Running = proc.isRunning()
+			status.Restarts = proc.restarts
+			if status.Running {
+				status.Pid = proc.cmd.Process.Pid
+				status.StartedAt = proc.startedAt.Format(time.RFC3339)
+			}
+			if proc.lastExit != nil {
+				status.LastExit = proc.lastExit.Error()
+			}
+			if !proc.lastExitAt.IsZero() {
+				status.LastExitAt = proc.lastExitAt.Format(time.RFC3339)
+			}
+			proc.mu.Unlock()
+		}
+		result[spec.ID] = status
+	}
+	return result
+}
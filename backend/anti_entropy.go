@@ -0,0 +1,54 @@
+package main
+
+import "sync"
+
+// BucketMerkleIndex caches each Merkle bucket's tree and only rebuilds a
+// bucket from storage when a write has touched one of its keys since the
+// last build - the dirty-leaf bitmap that lets merkleSyncWithNode skip a
+// full Storage.Iterate scan per bucket on every anti-entropy round when
+// most of the keyspace hasn't changed.
+type BucketMerkleIndex struct {
+	mu    sync.Mutex
+	trees map[int]*MerkleTree
+	dirty map[int]bool
+}
+
+func NewBucketMerkleIndex() *BucketMerkleIndex {
+	return &BucketMerkleIndex{
+		trees: make(map[int]*MerkleTree),
+		dirty: make(map[int]bool),
+	}
+}
+
+// MarkDirty flips the dirty bit for whichever bucket key hashes into.
+// It's wired up as BadgerStorage's write hook so a Put invalidates only
+// the one bucket it touched instead of the whole index.
+func (idx *BucketMerkleIndex) MarkDirty(key string) {
+	bucket := int(hashKey(key) % merkleBucketCount)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.dirty[bucket] = true
+}
+
+// Get returns the cached tree for bucket, rebuilding it via build only if
+// the bucket is dirty or has never been built.
+func (idx *BucketMerkleIndex) Get(bucket int, build func() *MerkleTree) *MerkleTree {
+	idx.mu.Lock()
+	tree, ok := idx.trees[bucket]
+	dirty := idx.dirty[bucket]
+	idx.mu.Unlock()
+
+	if ok && !dirty {
+		return tree
+	}
+
+	tree = build()
+
+	idx.mu.Lock()
+	idx.trees[bucket] = tree
+	idx.dirty[bucket] = false
+	idx.mu.Unlock()
+
+	return tree
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// BulkSyncItem is one key/value/vector-clock triple shipped over a
+// bulk-sync stream. It mirrors the body forceSyncKey used to send one PUT
+// per key, but many of these are written as newline-delimited JSON over a
+// single connection instead of opening a new request per key.
+type BulkSyncItem struct {
+	Key         string         `json:"key"`
+	Value       interface{}    `json:"value"`
+	VectorClock map[string]int `json:"vector_clock"`
+	ValueType   ValueType      `json:"value_type"`
+}
+
+// bulkSyncAck is streamed back one line per item so the sender can report
+// partial progress without waiting for the whole batch to finish.
+type bulkSyncAck struct {
+	Key   string `json:"key"`
+	Ok    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// bulkSyncToNode ships items to nodeID over a single streaming HTTP
+// request instead of issuing one PUT per key. The request body and the
+// response are both newline-delimited JSON, written and read
+// incrementally, so neither side has to buffer the full batch in memory.
+// It returns the number of items the peer acknowledged.
+func (c *Coordinator) bulkSyncToNode(nodeID string, items []BulkSyncItem) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		enc := json.NewEncoder(pw)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	url := fmt.Sprintf("http://%s:%d/internal/bulk-kv", getHost(nodeID), getPortForNode(nodeID))
+	req, err := http.NewRequest("POST", url, pr)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	// The batch can take a while to stream on a slow link; scale the
+	// timeout with its size instead of using the usual fixed RPC timeout.
+	timeout := requestTimeout + time.Duration(len(items))*50*time.Millisecond
+	client := newRPCClient(timeout)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	acked := 0
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ack bulkSyncAck
+		if err := json.Unmarshal(scanner.Bytes(), &ack); err != nil {
+			continue
+		}
+		if ack.Ok {
+			acked++
+		} else {
+			textLog(c.NodeID, "BULK_SYNC", "Peer %s rejected key %s: %s", nodeID, ack.Key, ack.Error)
+		}
+	}
+
+	return acked, scanner.Err()
+}
+
+// BulkSyncHandler reads a newline-delimited stream of BulkSyncItems,
+// force-storing each as it arrives and flushing an ack line immediately
+// rather than buffering the whole batch before responding. This backs
+// merkleSyncWithNode, which used to push one diffed key per HTTP request.
+func BulkSyncHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	decoder := json.NewDecoder(r.Body)
+	for {
+		var item BulkSyncItem
+		if err := decoder.Decode(&item); err != nil {
+			if err != io.EOF {
+				textLog(coordinator.NodeID, "BULK_SYNC", "Malformed bulk-sync item: %v", err)
+			}
+			break
+		}
+
+		sv := storedValue{
+			Value:       item.Value,
+			VectorClock: &VectorClock{Clock: item.VectorClock},
+			Timestamp:   time.Now(),
+			ValueType:   item.ValueType,
+		}
+
+		ack := bulkSyncAck{Key: item.Key, Ok: true}
+		if err := coordinator.Storage.Put(item.Key, sv); err != nil {
+			ack.Ok = false
+			ack.Error = err.Error()
+		}
+
+		enc.Encode(ack)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,48 @@ type PeerConfig struct {
 	Port   int    `json:"port"`
 }
 
+// Duration is a time.Duration that decodes from either a human-friendly
+// string (anything time.ParseDuration accepts, e.g. "500ms", "2s",
+// "1m30s") or a raw JSON number, which is interpreted as milliseconds so
+// config files written against the old *_ms integer fields still load
+// unchanged.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch v := raw.(type) {
+	case float64:
+		*d = Duration(time.Duration(v) * time.Millisecond)
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", v, err)
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("duration must be a number (milliseconds) or a string (e.g. \"500ms\"), got %T", raw)
+	}
+	return nil
+}
+
+// UnmarshalText lets Duration decode correctly under config formats that
+// encode scalars as text rather than JSON's tagged number/string (YAML
+// and TOML both consult encoding.TextUnmarshaler for a string node before
+// falling back to their own native number decoding), without either of
+// those formats needing a format-specific Duration method.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", text, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
 type Config struct {
 	NodeID               string       `json:"node_id"`
 	Host                 string       `json:"host"`
@@ -21,52 +64,158 @@ type Config struct {
 	ReplicationFactor    int          `json:"replication_factor"`
 	ReadQuorum           int          `json:"read_quorum"`
 	WriteQuorum          int          `json:"write_quorum"`
-	GossipInterval       time.Duration
-	FailureCheckInterval time.Duration
-	GossipTimeout        time.Duration
+	RespPort             int          `json:"resp_port"`
+	StorageURI           string       `json:"storage_uri"`
+	GRPCPort             int          `json:"grpc_port"`
+	UseGRPCTransport     bool         `json:"use_grpc_transport"`
+	ConflictPolicy       string       `json:"conflict_policy"`
+	GossipInterval       Duration     `json:"gossip_interval"`
+	FailureCheckInterval Duration     `json:"failure_check_interval"`
+	GossipTimeout        Duration     `json:"gossip_timeout"`
 }
 
-func LoadConfig(filename string) (*Config, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("config read error: %w", err)
+// durationEnvOverrides lists the env vars an operator can set to retune a
+// running cluster's gossip timing without editing its config file. Each
+// value is parsed with time.ParseDuration, same as the JSON string form.
+var durationEnvOverrides = []struct {
+	envVar string
+	field  func(*Config) *Duration
+}{
+	{"DYNAMO_GOSSIP_INTERVAL", func(c *Config) *Duration { return &c.GossipInterval }},
+	{"DYNAMO_FAILURE_CHECK_INTERVAL", func(c *Config) *Duration { return &c.FailureCheckInterval }},
+	{"DYNAMO_GOSSIP_TIMEOUT", func(c *Config) *Duration { return &c.GossipTimeout }},
+}
+
+// applyDurationEnvOverrides lets an operator override any gossip timing
+// field from the environment, e.g. DYNAMO_GOSSIP_INTERVAL=750ms, without
+// touching the config file on disk. Applied after the file loads and
+// before ValidateConfig runs, so an invalid override is still caught by
+// the same validation a bad JSON value would be.
+func applyDurationEnvOverrides(cfg *Config) error {
+	for _, o := range durationEnvOverrides {
+		raw := os.Getenv(o.envVar)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid %s=%q: %w", o.envVar, raw, err)
+		}
+		*o.field(cfg) = Duration(parsed)
 	}
+	return nil
+}
+
+// LoadConfig loads a Config from a plain JSON file path. It's kept as a
+// direct convenience wrapper over the general ConfigSource plumbing (see
+// config_source.go) for callers that only ever deal with local JSON and
+// don't care about -config's broader file://, yaml://, toml://, and
+// etcd:// URI forms.
+func LoadConfig(filename string) (*Config, error) {
+	return (&fileConfigSource{path: filename, decode: decodeJSONConfig}).Load()
+}
+
+// FieldError is one field-level validation failure, with a stable,
+// dotted/indexed path (e.g. "peers[2].port") so an operator can jump
+// straight to the offending line instead of re-reading the whole config.
+type FieldError struct {
+	Field  string
+	Value  interface{}
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s (got %v)", e.Field, e.Reason, e.Value)
+}
 
-	var configData struct {
-		*Config
-		GossipIntervalMs       int `json:"gossip_interval_ms"`
-		FailureCheckIntervalMs int `json:"failure_check_interval_ms"`
-		GossipTimeoutMs        int `json:"gossip_timeout_ms"`
+// ValidationErrors aggregates every FieldError one ValidateConfig pass
+// found. ValidateConfig collects all violations instead of returning on
+// the first one, so a bad config can be fixed in a single edit cycle
+// rather than one error at a time.
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.Error()
 	}
+	return fmt.Sprintf("%d config validation errors:\n  %s", len(errs), strings.Join(lines, "\n  "))
+}
 
-	if err := json.Unmarshal(data, &configData); err != nil {
-		return nil, fmt.Errorf("config parse error: %w", err)
+func ValidateConfig(cfg *Config) error {
+	var errs ValidationErrors
+	addErr := func(field string, value interface{}, reason string) {
+		errs = append(errs, FieldError{Field: field, Value: value, Reason: reason})
 	}
 
-	configData.Config.GossipInterval = time.Duration(configData.GossipIntervalMs) * time.Millisecond
-	configData.Config.FailureCheckInterval = time.Duration(configData.FailureCheckIntervalMs) * time.Millisecond
-	configData.Config.GossipTimeout = time.Duration(configData.GossipTimeoutMs) * time.Millisecond
+	if cfg.NodeID == "" {
+		addErr("node_id", cfg.NodeID, "must not be empty")
+	}
+	if cfg.Host == "" {
+		addErr("host", cfg.Host, "must not be empty")
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		addErr("port", cfg.Port, "must be between 1 and 65535")
+	}
 
-	if err := ValidateConfig(configData.Config); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+	seenPeerIDs := make(map[string]int, len(cfg.Peers)) // nodeID -> first index it appeared at
+	for i, peer := range cfg.Peers {
+		switch first, dup := seenPeerIDs[peer.NodeID]; {
+		case peer.NodeID == "":
+			addErr(fmt.Sprintf("peers[%d].node_id", i), peer.NodeID, "must not be empty")
+		case dup:
+			addErr(fmt.Sprintf("peers[%d].node_id", i), peer.NodeID, fmt.Sprintf("duplicates peers[%d].node_id", first))
+		default:
+			seenPeerIDs[peer.NodeID] = i
+		}
+		if peer.Host == "" {
+			addErr(fmt.Sprintf("peers[%d].host", i), peer.Host, "must not be empty")
+		}
+		if peer.Port < 1 || peer.Port > 65535 {
+			addErr(fmt.Sprintf("peers[%d].port", i), peer.Port, "must be between 1 and 65535")
+		}
 	}
 
-	return configData.Config, nil
-}
+	if cfg.ReadQuorum <= 0 {
+		addErr("read_quorum", cfg.ReadQuorum, "must be a positive integer")
+	}
+	if cfg.WriteQuorum <= 0 {
+		addErr("write_quorum", cfg.WriteQuorum, "must be a positive integer")
+	}
 
-func ValidateConfig(cfg *Config) error {
-	if cfg.ReadQuorum <= 0 || cfg.WriteQuorum <= 0 {
-		return fmt.Errorf("quorums must be positive integers")
+	maxReplicas := len(cfg.Peers) + 1
+	if cfg.ReplicationFactor > maxReplicas {
+		addErr("replication_factor", cfg.ReplicationFactor, fmt.Sprintf("cannot exceed len(peers)+1 (%d)", maxReplicas))
 	}
 
-	if sum := cfg.ReadQuorum + cfg.WriteQuorum; sum <= cfg.ReplicationFactor {
-		return fmt.Errorf("unsafe quorum: R(%d) + W(%d) â‰¤ N(%d)",
-			cfg.ReadQuorum, cfg.WriteQuorum, cfg.ReplicationFactor)
+	if cfg.ReadQuorum > 0 && cfg.WriteQuorum > 0 {
+		if sum := cfg.ReadQuorum + cfg.WriteQuorum; sum <= cfg.ReplicationFactor {
+			addErr("read_quorum+write_quorum", sum, fmt.Sprintf("must exceed replication_factor (%d) so a read and a write always intersect", cfg.ReplicationFactor))
+		}
+		if cfg.WriteQuorum*2 <= cfg.ReplicationFactor {
+			addErr("write_quorum", cfg.WriteQuorum, fmt.Sprintf("must be greater than replication_factor/2 (%d) so two concurrent writes always intersect", cfg.ReplicationFactor/2))
+		}
 	}
 
-	if cfg.GossipInterval < 100*time.Millisecond {
-		return fmt.Errorf("gossip interval too short: %v", cfg.GossipInterval)
+	gossipInterval := time.Duration(cfg.GossipInterval)
+	failureCheckInterval := time.Duration(cfg.FailureCheckInterval)
+	gossipTimeout := time.Duration(cfg.GossipTimeout)
+
+	if gossipInterval < 100*time.Millisecond {
+		addErr("gossip_interval", gossipInterval.String(), "must be at least 100ms")
+	}
+	if gossipTimeout <= gossipInterval {
+		addErr("gossip_timeout", gossipTimeout.String(), fmt.Sprintf("must be greater than gossip_interval (%v)", gossipInterval))
+	}
+	if failureCheckInterval < 2*gossipInterval {
+		addErr("failure_check_interval", failureCheckInterval.String(), fmt.Sprintf("must be at least 2x gossip_interval (%v)", gossipInterval))
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
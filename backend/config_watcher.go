@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FieldChange is one field's outcome from a reload attempt: either the
+// live value it moved to, or - if OldValue/NewValue are left zero and
+// Reason is set - a rejection explaining why that field needs a restart.
+type FieldChange struct {
+	Field    string      `json:"field"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+	Reason   string      `json:"reason,omitempty"`
+}
+
+// ReloadResult is what one Reload() produces: every field the new config
+// touched, split into what was actually applied live and what was
+// rejected as restart-only.
+type ReloadResult struct {
+	Applied  []FieldChange `json:"applied"`
+	Rejected []FieldChange `json:"rejected"`
+}
+
+// ConfigWatcher watches a ConfigSource for changes and, on each one,
+// applies whatever of the result can be changed live - GossipInterval,
+// FailureCheckInterval, GossipTimeout, ReadQuorum, WriteQuorum, and
+// ReplicationFactor when it wouldn't violate current membership -
+// directly onto the attached Coordinator/GossipService. Fields that
+// can't be changed without a restart (NodeID, Host, Port, StorageURI,
+// GRPCPort, RespPort, UseGRPCTransport, ConflictPolicy) are rejected with
+// a reason instead of silently ignored.
+//
+// ConfigWatcher itself doesn't know or care whether source is backed by
+// a local file, a YAML/TOML file, or an etcd key - see config_source.go.
+//
+// Subscribe lets other subsystems observe every applied reload over a
+// channel, the same shape source.Watch pushes through.
+type ConfigWatcher struct {
+	source      ConfigSource
+	coordinator *Coordinator
+	stopCh      chan struct{}
+
+	mu          sync.Mutex
+	current     *Config
+	subscribers []chan *Config
+}
+
+// NewConfigWatcher returns a ConfigWatcher seeded with the already-loaded
+// initial Config. Call Start to begin reacting to changes from source.
+func NewConfigWatcher(source ConfigSource, initial *Config, coordinator *Coordinator) (*ConfigWatcher, error) {
+	return &ConfigWatcher{
+		source:      source,
+		coordinator: coordinator,
+		stopCh:      make(chan struct{}),
+		current:     initial,
+	}, nil
+}
+
+// Start runs source.Watch in the background until Stop is called. A
+// reload that fails (bad data, or ValidateConfig rejecting it) is logged
+// by the source itself and never reaches here - see source.Watch.
+func (cw *ConfigWatcher) Start() {
+	updates, err := cw.source.Watch(cw.stopCh)
+	if err != nil {
+		textLog(cw.coordinator.NodeID, "CONFIG_RELOAD", "starting config watch failed: %v", err)
+		return
+	}
+	go func() {
+		for next := range updates {
+			cw.apply(next)
+		}
+	}()
+}
+
+// Stop ends the watch loop.
+func (cw *ConfigWatcher) Stop() {
+	close(cw.stopCh)
+}
+
+// Subscribe registers a channel that receives every Config a reload
+// actually applies (even if only partially). The channel is buffered by
+// one and a pending-but-unread value is overwritten rather than blocking
+// the watcher loop.
+func (cw *ConfigWatcher) Subscribe() <-chan *Config {
+	ch := make(chan *Config, 1)
+	cw.mu.Lock()
+	cw.subscribers = append(cw.subscribers, ch)
+	cw.mu.Unlock()
+	return ch
+}
+
+// Current returns the Config as of the last successful reload (or the
+// initial one, if none have happened yet).
+func (cw *ConfigWatcher) Current() *Config {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return cw.current
+}
+
+// Reload re-runs source.Load and applies whatever of the result is safe
+// to change live. It's the same effect Start's background loop has on a
+// source-observed change, exposed directly so ReloadConfigHandler can
+// trigger it synchronously and return the result.
+func (cw *ConfigWatcher) Reload() (*ReloadResult, error) {
+	next, err := cw.source.Load()
+	if err != nil {
+		return nil, err
+	}
+	return cw.apply(next), nil
+}
+
+// apply diffs next against the last-applied Config, rejecting restart-only
+// fields that changed and applying the rest directly onto the Coordinator
+// and its GossipService, then notifies subscribers.
+func (cw *ConfigWatcher) apply(next *Config) *ReloadResult {
+	cw.mu.Lock()
+	prev := cw.current
+	result := &ReloadResult{}
+
+	reject := func(field string, reason string) {
+		result.Rejected = append(result.Rejected, FieldChange{Field: field, Reason: reason})
+	}
+	accept := func(field string, old, new interface{}) {
+		result.Applied = append(result.Applied, FieldChange{Field: field, OldValue: old, NewValue: new})
+	}
+
+	if next.NodeID != prev.NodeID {
+		reject("node_id", "node identity cannot change without a restart")
+	}
+	if next.Host != prev.Host || next.Port != prev.Port {
+		reject("host/port", "listen address cannot change without a restart")
+	}
+	if next.StorageURI != prev.StorageURI {
+		reject("storage_uri", "storage backend cannot change without a restart")
+	}
+	if next.GRPCPort != prev.GRPCPort {
+		reject("grpc_port", "internal transport listener cannot change without a restart")
+	}
+	if next.RespPort != prev.RespPort {
+		reject("resp_port", "RESP listener cannot change without a restart")
+	}
+	if next.UseGRPCTransport != prev.UseGRPCTransport {
+		reject("use_grpc_transport", "transport selection cannot change without a restart")
+	}
+	if next.ConflictPolicy != prev.ConflictPolicy {
+		reject("conflict_policy", "conflict policy cannot change without a restart")
+	}
+
+	if next.ReplicationFactor != prev.ReplicationFactor {
+		memberCount := len(cw.coordinator.Ring.getAllNodeIDs())
+		if next.ReplicationFactor > memberCount {
+			reject("replication_factor", fmt.Sprintf("replication factor %d would exceed current membership size (%d nodes)", next.ReplicationFactor, memberCount))
+		} else {
+			cw.coordinator.Replication = next.ReplicationFactor
+			accept("replication_factor", prev.ReplicationFactor, next.ReplicationFactor)
+		}
+	}
+
+	if next.ReadQuorum != prev.ReadQuorum {
+		cw.coordinator.ReadQuorum = next.ReadQuorum
+		accept("read_quorum", prev.ReadQuorum, next.ReadQuorum)
+	}
+	if next.WriteQuorum != prev.WriteQuorum {
+		cw.coordinator.WriteQuorum = next.WriteQuorum
+		accept("write_quorum", prev.WriteQuorum, next.WriteQuorum)
+	}
+
+	if gi := time.Duration(next.GossipInterval); gi != time.Duration(prev.GossipInterval) {
+		cw.coordinator.Gossip.SetGossipInterval(gi)
+		accept("gossip_interval", time.Duration(prev.GossipInterval).String(), gi.String())
+	}
+	if fci := time.Duration(next.FailureCheckInterval); fci != time.Duration(prev.FailureCheckInterval) {
+		cw.coordinator.Gossip.SetFailureCheckInterval(fci)
+		accept("failure_check_interval", time.Duration(prev.FailureCheckInterval).String(), fci.String())
+	}
+	if gt := time.Duration(next.GossipTimeout); gt != time.Duration(prev.GossipTimeout) {
+		cw.coordinator.Gossip.SetGossipTimeout(gt)
+		accept("gossip_timeout", time.Duration(prev.GossipTimeout).String(), gt.String())
+	}
+
+	cw.current = next
+	subs := append([]chan *Config(nil), cw.subscribers...)
+	cw.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- next:
+		default:
+			// Buffer full: drop the stale pending value and send next.
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- next:
+			default:
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ConflictPolicy names one of the resolution strategies below. It is the
+// string a Config/flag picks the resolver by.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyMerge is the original behavior: keep the first value
+	// seen, merge in the other side's vector clock, and record the other
+	// value under Conflicts for the caller to inspect.
+	ConflictPolicyMerge ConflictPolicy = "merge"
+	// ConflictPolicyLWW discards the losing value outright, keeping
+	// whichever write has the later wall-clock Timestamp.
+	ConflictPolicyLWW ConflictPolicy = "lww"
+	// ConflictPolicyCRDT keeps every concurrent value, deduplicated and
+	// ordered by value, so every replica computes the same result
+	// regardless of the order concurrent writes were observed in.
+	ConflictPolicyCRDT ConflictPolicy = "crdt"
+)
+
+// ConflictResolver picks (or merges into) a single storedValue from two
+// values a vector-clock comparison found concurrent. Coordinator.Get
+// calls this instead of hardcoding one strategy, so PUT/GET conflict
+// handling can be swapped per deployment.
+type ConflictResolver interface {
+	Resolve(a, b storedValue) storedValue
+}
+
+// resolverForPolicy maps a policy name to its ConflictResolver,
+// defaulting to ConflictPolicyMerge for an empty or unrecognized value so
+// existing deployments that don't set one keep today's behavior.
+func resolverForPolicy(policy ConflictPolicy) ConflictResolver {
+	switch policy {
+	case ConflictPolicyLWW:
+		return LWWResolver{}
+	case ConflictPolicyCRDT:
+		return CRDTRegisterResolver{}
+	default:
+		return MergeResolver{}
+	}
+}
+
+// MergeResolver is the pre-existing strategy: keep a's value, merge in
+// b's vector clock, and append b to the Conflicts list so callers can
+// still see every concurrent write that was made.
+type MergeResolver struct{}
+
+func (MergeResolver) Resolve(a, b storedValue) storedValue {
+	merged := a
+	merged.VectorClock = a.VectorClock.Clone()
+	merged.VectorClock.Merge(b.VectorClock)
+	merged.Conflicts = append(a.Conflicts, b)
+	merged.Timestamp = time.Now()
+	return merged
+}
+
+// LWWResolver resolves concurrent writes by wall-clock Timestamp,
+// discarding the loser entirely. Simple and cheap, but vulnerable to
+// clock skew between nodes the way any last-writer-wins scheme is.
+type LWWResolver struct{}
+
+func (LWWResolver) Resolve(a, b storedValue) storedValue {
+	winner := a
+	if b.Timestamp.After(a.Timestamp) {
+		winner = b
+	}
+	winner.VectorClock = a.VectorClock.Clone()
+	winner.VectorClock.Merge(b.VectorClock)
+	winner.Conflicts = nil
+	return winner
+}
+
+// CRDTRegisterResolver implements a multi-value register along the
+// lines of Riak's CRDT value type: instead of picking a winner, it keeps
+// every causally-concurrent value (deduplicated and sorted so the result
+// is identical no matter which replica computes it, or in what order it
+// sees the two inputs), leaving the caller to merge application-side.
+// This only behaves as a true CRDT - same result regardless of merge
+// order - because the dedup key is the value's own representation, not
+// arrival order.
+type CRDTRegisterResolver struct{}
+
+func (CRDTRegisterResolver) Resolve(a, b storedValue) storedValue {
+	values := map[string]storedValue{crdtValueKey(a): a}
+	for _, c := range a.Conflicts {
+		values[crdtValueKey(c)] = c
+	}
+	if _, exists := values[crdtValueKey(b)]; !exists {
+		values[crdtValueKey(b)] = b
+	}
+	for _, c := range b.Conflicts {
+		if _, exists := values[crdtValueKey(c)]; !exists {
+			values[crdtValueKey(c)] = c
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	primary := values[keys[0]]
+	merged := primary
+	merged.VectorClock = a.VectorClock.Clone()
+	merged.VectorClock.Merge(b.VectorClock)
+	merged.Conflicts = merged.Conflicts[:0]
+	for _, k := range keys[1:] {
+		merged.Conflicts = append(merged.Conflicts, values[k])
+	}
+	return merged
+}
+
+func crdtValueKey(sv storedValue) string {
+	return fmt.Sprintf("%v", sv.Value)
+}
@@ -9,24 +9,59 @@ import (
 
 const (
 	virtualNodeCount = 256
-	replicaCount     = 3
+
+	// defaultReplicaCount and defaultEpsilon seed a freshly constructed
+	// ring's ReplicaCount/Epsilon. Both are instance fields rather than
+	// hard constants so a cluster can tune them without a recompile.
+	defaultReplicaCount = 3
+	defaultEpsilon      = 0.25
 )
 
+// LoadProvider reports a physical node's current key count so the ring's
+// bounded-load mode can skip candidates that are already carrying more
+// than their fair share. It's expected to be backed by NodeStats.KeyCount
+// gossiped in from peers - the ring itself has no reach into per-node
+// storage stats.
+type LoadProvider func(nodeID string) int64
+
 // ConsistentHashRing implements consistent hashing for distributed data partitioning
 type ConsistentHashRing struct {
 	mu           sync.RWMutex
 	virtualNodes []uint64
 	nodeMap      map[uint64]string
 	nodes        map[string]bool
+
+	// Epsilon bounds how far above the cluster's average key count a
+	// node may climb before GetNode/GetReplicas skip it in favor of the
+	// next virtual node on the ring. Bounded-load skipping only takes
+	// effect once Load is also set; Epsilon <= 0 disables it regardless.
+	Epsilon float64
+	// ReplicaCount is the replica fan-out GetReplicas falls back to when
+	// called with n <= 0.
+	ReplicaCount int
+	// Load, when set, enables bounded-load placement; nil keeps GetNode/
+	// GetReplicas at pure hash placement.
+	Load LoadProvider
 }
 
 func NewConsistentHashRing() *ConsistentHashRing {
 	return &ConsistentHashRing{
-		nodeMap: make(map[uint64]string),
-		nodes:   make(map[string]bool),
+		nodeMap:      make(map[uint64]string),
+		nodes:        make(map[string]bool),
+		Epsilon:      defaultEpsilon,
+		ReplicaCount: defaultReplicaCount,
 	}
 }
 
+// SetLoadProvider wires fn as the ring's load source, enabling bounded-
+// load placement in GetNode/GetReplicas. Passing nil reverts to pure hash
+// placement.
+func (c *ConsistentHashRing) SetLoadProvider(fn LoadProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Load = fn
+}
+
 func (c *ConsistentHashRing) AddNode(nodeID string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -59,6 +94,11 @@ func (c *ConsistentHashRing) RemoveNode(nodeID string) {
 	c.virtualNodes = newVirtualNodes
 }
 
+// GetNode returns the physical node responsible for key: the first node
+// walking clockwise from key's hash position whose current load (per
+// Load) still fits under (1+Epsilon)*average. If every node is over the
+// bound, or Load/Epsilon isn't configured, it degrades to plain hash
+// placement rather than refuse to route the key at all.
 func (c *ConsistentHashRing) GetNode(key string) string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -67,16 +107,104 @@ func (c *ConsistentHashRing) GetNode(key string) string {
 		return ""
 	}
 
+	idx := c.ringIndexLocked(key)
+	fallback := c.nodeMap[c.virtualNodes[idx]]
+
+	avg, haveAvg := c.averageLoadLocked()
+	for i := 0; i < len(c.virtualNodes); i++ {
+		candidate := c.nodeMap[c.virtualNodes[(idx+i)%len(c.virtualNodes)]]
+		if c.withinBoundLocked(candidate, avg, haveAvg) {
+			return candidate
+		}
+	}
+	return fallback
+}
+
+// GetReplicas returns the next n distinct physical nodes walking the ring
+// clockwise from key's hash position, deduping virtual nodes that belong
+// to the same physical node. In bounded-load mode it first tries to fill
+// the n slots with nodes under (1+Epsilon)*average, then makes a second
+// pass ignoring the bound to fill any slots that are still short - an
+// overloaded replica beats failing the read/write outright. n <= 0 uses
+// c.ReplicaCount.
+func (c *ConsistentHashRing) GetReplicas(key string, n int) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if n <= 0 {
+		n = c.ReplicaCount
+	}
+	if len(c.virtualNodes) == 0 || n == 0 {
+		return nil
+	}
+
+	idx := c.ringIndexLocked(key)
+	avg, haveAvg := c.averageLoadLocked()
+	seen := make(map[string]bool, n)
+	replicas := make([]string, 0, n)
+
+	collect := func(respectBound bool) {
+		for i := 0; i < len(c.virtualNodes) && len(replicas) < n; i++ {
+			candidate := c.nodeMap[c.virtualNodes[(idx+i)%len(c.virtualNodes)]]
+			if seen[candidate] {
+				continue
+			}
+			if respectBound && !c.withinBoundLocked(candidate, avg, haveAvg) {
+				continue
+			}
+			seen[candidate] = true
+			replicas = append(replicas, candidate)
+		}
+	}
+
+	collect(true)
+	if len(replicas) < n {
+		collect(false)
+	}
+
+	return replicas
+}
+
+// ringIndexLocked returns the virtualNodes index of the first slot at or
+// past key's hash, wrapping to 0 past the end of the ring. Callers must
+// hold c.mu and have already checked len(c.virtualNodes) > 0.
+func (c *ConsistentHashRing) ringIndexLocked(key string) int {
 	hash := hashKey(key)
 	idx := sort.Search(len(c.virtualNodes), func(i int) bool {
 		return c.virtualNodes[i] >= hash
 	})
-
 	if idx == len(c.virtualNodes) {
 		idx = 0
 	}
+	return idx
+}
+
+// averageLoadLocked returns the mean Load across all known physical
+// nodes. The second return value is false (disabling bounded-load
+// skipping) when Load isn't set or no nodes are known yet. Callers must
+// hold at least a read lock on c.mu.
+func (c *ConsistentHashRing) averageLoadLocked() (int64, bool) {
+	if c.Load == nil || len(c.nodes) == 0 {
+		return 0, false
+	}
+	var total int64
+	for nodeID := range c.nodes {
+		total += c.Load(nodeID)
+	}
+	return total / int64(len(c.nodes)), true
+}
 
-	return c.nodeMap[c.virtualNodes[idx]]
+// withinBoundLocked reports whether nodeID's current load still fits
+// under (1+Epsilon)*avg - the bounded-load admission test GetNode/
+// GetReplicas apply to each ring candidate in turn. It's a no-op pass
+// whenever bounded-load mode isn't fully configured (no average yet,
+// Epsilon disabled, or the cluster hasn't accumulated any load).
+func (c *ConsistentHashRing) withinBoundLocked(nodeID string, avg int64, haveAvg bool) bool {
+	if !haveAvg || c.Epsilon <= 0 || avg == 0 {
+		return true
+	}
+	threshold := int64((1 + c.Epsilon) * float64(avg))
+	return c.Load(nodeID) <= threshold
 }
 
 func (c *ConsistentHashRing) getAllNodeIDs() []string {
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ControllerOpType mirrors the shardctrler pattern: membership and
+// ownership changes are modeled as a small set of auditable ops rather
+// than ad-hoc gossip state.
+type ControllerOpType string
+
+const (
+	OpJoin  ControllerOpType = "join"
+	OpLeave ControllerOpType = "leave"
+	OpMove  ControllerOpType = "move"
+	OpQuery ControllerOpType = "query"
+)
+
+// ControllerLogEntry is one replicated log entry. In a full deployment
+// this would be the payload carried by a 3-5 node Raft group; here it is
+// the unit that Controller.Propose appends and replays to produce the
+// next RingConfig.
+type ControllerLogEntry struct {
+	Index         int
+	Op            ControllerOpType
+	NodeID        string // for Join/Leave
+	Host          string // for Join
+	Port          int    // for Join
+	Key           string // for Move (key-range override)
+	OwnerOverride string // for Move
+}
+
+// PeerAddr is where a member can be reached for internal RPCs. Join
+// entries carry one so the controller's membership list can answer "how
+// do I reach node X" directly instead of every call site deriving it
+// from a hardcoded nodeID->port table.
+type PeerAddr struct {
+	Host string
+	Port int
+}
+
+// RingConfig is a versioned snapshot of ring membership, replication
+// factor, and any per-key-range ownership overrides. Nodes must refuse to
+// serve requests tagged with a stale epoch.
+type RingConfig struct {
+	Epoch       int
+	Members     []string
+	Addrs       map[string]PeerAddr // nodeID -> where to reach it
+	Replication int
+	Overrides   map[string]string // key -> nodeID override
+}
+
+// Controller replicates membership and shard-assignment decisions through
+// a small log so joins/leaves become linearizable proposals rather than
+// gossip races. It does not implement full Raft leader election; it
+// assumes it is constructed on whichever node currently holds leadership,
+// matching how the rest of this codebase keeps consensus concerns out of
+// the hot read/write path.
+type Controller struct {
+	mu     sync.Mutex
+	log    []ControllerLogEntry
+	config RingConfig
+	subs   []func(RingConfig)
+}
+
+// NewController seeds the controller with the cluster's starting
+// membership, reachable addresses, and replication factor at epoch 0.
+func NewController(members []string, addrs map[string]PeerAddr, replication int) *Controller {
+	seeded := make(map[string]PeerAddr, len(addrs))
+	for k, v := range addrs {
+		seeded[k] = v
+	}
+	return &Controller{
+		config: RingConfig{
+			Epoch:       0,
+			Members:     append([]string{}, members...),
+			Addrs:       seeded,
+			Replication: replication,
+			Overrides:   make(map[string]string),
+		},
+	}
+}
+
+// Propose appends an op to the replicated log and applies it, publishing
+// the resulting RingConfig to every subscriber. Move, and any Join/Leave
+// that changes membership or addresses, bump the epoch; Query is a
+// read-only no-op included for completeness with the shardctrler-style API.
+func (ctl *Controller) Propose(op ControllerLogEntry) RingConfig {
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+
+	op.Index = len(ctl.log)
+	ctl.log = append(ctl.log, op)
+
+	switch op.Op {
+	case OpJoin:
+		ctl.config = ctl.applyJoin(ctl.config, op.NodeID, op.Host, op.Port)
+	case OpLeave:
+		ctl.config = ctl.applyLeave(ctl.config, op.NodeID)
+	case OpMove:
+		ctl.config = ctl.applyMove(ctl.config, op.Key, op.OwnerOverride)
+	case OpQuery:
+		// No state change; callers use this to fetch the current config.
+	}
+
+	cfg := ctl.config
+	for _, sub := range ctl.subs {
+		sub(cfg)
+	}
+	return cfg
+}
+
+func (ctl *Controller) applyJoin(cfg RingConfig, nodeID, host string, port int) RingConfig {
+	_, known := cfg.Addrs[nodeID]
+	alreadyMember := false
+	for _, m := range cfg.Members {
+		if m == nodeID {
+			alreadyMember = true
+			break
+		}
+	}
+	if alreadyMember && (known || host == "") {
+		return cfg // already a member with no new address info, no epoch change
+	}
+
+	next := cloneRingConfig(cfg)
+	next.Epoch++
+	if !alreadyMember {
+		next.Members = append(next.Members, nodeID)
+	}
+	if host != "" {
+		next.Addrs[nodeID] = PeerAddr{Host: host, Port: port}
+	}
+	return next
+}
+
+func (ctl *Controller) applyLeave(cfg RingConfig, nodeID string) RingConfig {
+	idx := -1
+	for i, m := range cfg.Members {
+		if m == nodeID {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return cfg
+	}
+	next := cloneRingConfig(cfg)
+	next.Epoch++
+	next.Members = append(next.Members[:idx], next.Members[idx+1:]...)
+	delete(next.Addrs, nodeID)
+	return next
+}
+
+func (ctl *Controller) applyMove(cfg RingConfig, key, owner string) RingConfig {
+	next := cloneRingConfig(cfg)
+	next.Epoch++
+	next.Overrides[key] = owner
+	return next
+}
+
+func cloneRingConfig(cfg RingConfig) RingConfig {
+	members := append([]string{}, cfg.Members...)
+	addrs := make(map[string]PeerAddr, len(cfg.Addrs))
+	for k, v := range cfg.Addrs {
+		addrs[k] = v
+	}
+	overrides := make(map[string]string, len(cfg.Overrides))
+	for k, v := range cfg.Overrides {
+		overrides[k] = v
+	}
+	return RingConfig{
+		Epoch:       cfg.Epoch,
+		Members:     members,
+		Addrs:       addrs,
+		Replication: cfg.Replication,
+		Overrides:   overrides,
+	}
+}
+
+// CurrentConfig returns the latest committed RingConfig.
+func (ctl *Controller) CurrentConfig() RingConfig {
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	return ctl.config
+}
+
+// Subscribe registers a callback invoked with every new RingConfig
+// produced by Propose. Node.AttachController uses this to refuse serving
+// requests tagged with a stale config epoch.
+func (ctl *Controller) Subscribe(fn func(RingConfig)) {
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	ctl.subs = append(ctl.subs, fn)
+}
+
+// ControllerHandler exposes Join/Leave/Move/Query as a single admin
+// endpoint so operators can script membership changes the same way they
+// already script freeze/sync.
+func ControllerHandler(w http.ResponseWriter, r *http.Request) {
+	if coordinator.Controller == nil {
+		http.Error(w, "controller not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var body struct {
+		Op            string `json:"op"`
+		NodeID        string `json:"node_id"`
+		Host          string `json:"host"`
+		Port          int    `json:"port"`
+		Key           string `json:"key"`
+		OwnerOverride string `json:"owner_override"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	cfg := coordinator.Controller.Propose(ControllerLogEntry{
+		Op:            ControllerOpType(body.Op),
+		NodeID:        body.NodeID,
+		Host:          body.Host,
+		Port:          body.Port,
+		Key:           body.Key,
+		OwnerOverride: body.OwnerOverride,
+	})
+
+	// Piggyback the membership change on gossip's broadcast plane so the
+	// rest of the cluster hears about it well before the next Merkle
+	// anti-entropy pass, without a bespoke notification endpoint.
+	if gs := coordinator.Gossip; gs != nil {
+		switch ControllerOpType(body.Op) {
+		case OpJoin:
+			gs.Broadcasts.Enqueue("NodeJoin", body.NodeID, body.NodeID, gs.clusterSize())
+		case OpLeave:
+			gs.Broadcasts.Enqueue("NodeLeave", body.NodeID, body.NodeID, gs.clusterSize())
+		}
+	}
+
+	js, err := json.Marshal(cfg)
+	if err != nil {
+		http.Error(w, "Failed to marshal ring config", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
+// ErrStaleConfigEpoch is returned when a request is tagged with a
+// configEpoch older than the receiver's current one.
+type ErrStaleConfigEpoch struct {
+	Requested int
+	Current   int
+}
+
+func (e *ErrStaleConfigEpoch) Error() string {
+	return fmt.Sprintf("stale config epoch: request had %d, node is at %d", e.Requested, e.Current)
+}
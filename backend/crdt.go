@@ -0,0 +1,278 @@
+package main
+
+import "encoding/json"
+
+// ValueType tags what merge semantics a storedValue's payload follows.
+// The zero value and ValueTypeLWW both mean "opaque blob, last-writer-
+// wins" - the behavior every key had before CRDT types existed. The
+// other values select a storedValue.Merge dispatch that converges
+// deterministically instead of picking a winner and discarding the loser.
+type ValueType string
+
+const (
+	ValueTypeLWW         ValueType = "lww"
+	ValueTypeGCounter    ValueType = "gcounter"
+	ValueTypePNCounter   ValueType = "pncounter"
+	ValueTypeORSet       ValueType = "orset"
+	ValueTypeLWWRegister ValueType = "lwwregister"
+)
+
+// isCRDT reports whether vt has a Merge dispatch, i.e. concurrent writes
+// of this type should be merged instead of appended to Conflicts.
+func (vt ValueType) isCRDT() bool {
+	switch vt {
+	case ValueTypeGCounter, ValueTypePNCounter, ValueTypeORSet, ValueTypeLWWRegister:
+		return true
+	default:
+		return false
+	}
+}
+
+// Merge combines sv with a causally-concurrent storedValue of the same
+// CRDT ValueType, per that type's merge rule. Callers are expected to
+// only reach here once VectorClock.Compare has already reported the two
+// sides concurrent (0); Merge itself trusts sv.ValueType and doesn't
+// re-check causality beyond the vector clock it folds into the result.
+func (sv storedValue) Merge(other storedValue) storedValue {
+	merged := sv
+	merged.VectorClock = sv.VectorClock.Clone()
+	merged.VectorClock.Merge(other.VectorClock)
+	merged.Conflicts = nil
+
+	switch sv.ValueType {
+	case ValueTypeGCounter:
+		merged.Value = mergeGCounter(sv.Value, other.Value)
+	case ValueTypePNCounter:
+		merged.Value = mergePNCounter(sv.Value, other.Value)
+	case ValueTypeORSet:
+		merged.Value = mergeORSet(sv.Value, other.Value)
+	case ValueTypeLWWRegister:
+		merged.Value = mergeLWWRegister(sv, other)
+	}
+	return merged
+}
+
+// mergeLWWRegister picks the causally-later value by vector clock, and
+// when the two clocks are themselves concurrent (possible when Merge is
+// reached via a third replica's view rather than a direct pairwise
+// comparison), falls back to the later wall-clock Timestamp.
+func mergeLWWRegister(sv, other storedValue) interface{} {
+	switch sv.VectorClock.Compare(other.VectorClock) {
+	case 1:
+		return sv.Value
+	case -1:
+		return other.Value
+	default:
+		if other.Timestamp.After(sv.Timestamp) {
+			return other.Value
+		}
+		return sv.Value
+	}
+}
+
+// counterMap is the wire/storage shape of a G-Counter: one monotonic
+// count per node that has ever incremented it.
+type counterMap map[string]int64
+
+// decodeCounterMap normalizes a storedValue payload into a counterMap
+// regardless of whether it arrived as a native counterMap (same-process
+// merge) or as the map[string]interface{} a JSON round trip over the
+// wire produces.
+func decodeCounterMap(v interface{}) counterMap {
+	out := make(counterMap)
+	switch m := v.(type) {
+	case counterMap:
+		for node, count := range m {
+			out[node] = count
+		}
+	case map[string]int64:
+		for node, count := range m {
+			out[node] = count
+		}
+	case map[string]interface{}:
+		for node, raw := range m {
+			out[node] = toInt64(raw)
+		}
+	}
+	return out
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+// mergeCounterMaps merges two G-Counter payloads by per-node max, the
+// standard G-Counter merge: each node's own count only ever moves
+// forward, so taking the max per node can't lose an increment either
+// side has already observed.
+func mergeCounterMaps(a, b counterMap) counterMap {
+	merged := make(counterMap, len(a))
+	for node, count := range a {
+		merged[node] = count
+	}
+	for node, count := range b {
+		if count > merged[node] {
+			merged[node] = count
+		}
+	}
+	return merged
+}
+
+// mergeGCounter merges two G-Counter payloads.
+func mergeGCounter(a, b interface{}) counterMap {
+	return mergeCounterMaps(decodeCounterMap(a), decodeCounterMap(b))
+}
+
+// gcounterTotal sums a G-Counter payload's per-node counts into the
+// counter's current value.
+func gcounterTotal(v interface{}) int64 {
+	var total int64
+	for _, count := range decodeCounterMap(v) {
+		total += count
+	}
+	return total
+}
+
+// BuildGCounterDelta folds +delta into nodeID's slot of an existing
+// G-Counter payload (nil/absent current just starts a fresh counter),
+// the construction IncrementHandler uses to turn a client's delta into a
+// gcounter-typed storedValue.
+func BuildGCounterDelta(nodeID string, current interface{}, delta int64) counterMap {
+	counts := decodeCounterMap(current)
+	counts[nodeID] += delta
+	return counts
+}
+
+// pnCounterValue is a PN-Counter: a pair of G-Counters tracking
+// increments (P) and decrements (N) separately so merges never lose a
+// concurrent decrement the way a single signed counter would.
+type pnCounterValue struct {
+	P counterMap `json:"p"`
+	N counterMap `json:"n"`
+}
+
+// decodePNCounter normalizes a storedValue payload into a pnCounterValue,
+// same rationale as decodeCounterMap: same-process merges see the native
+// struct, wire-decoded ones see map[string]interface{}.
+func decodePNCounter(v interface{}) pnCounterValue {
+	switch pv := v.(type) {
+	case pnCounterValue:
+		return pv
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return pnCounterValue{}
+		}
+		var decoded pnCounterValue
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return pnCounterValue{}
+		}
+		return decoded
+	}
+}
+
+// mergePNCounter merges two PN-Counter payloads by merging P and N
+// independently, each with the standard G-Counter per-node-max rule.
+func mergePNCounter(a, b interface{}) pnCounterValue {
+	av, bv := decodePNCounter(a), decodePNCounter(b)
+	return pnCounterValue{
+		P: mergeCounterMaps(av.P, bv.P),
+		N: mergeCounterMaps(av.N, bv.N),
+	}
+}
+
+// pnCounterTotal is the PN-Counter's current value: total increments
+// minus total decrements.
+func pnCounterTotal(v pnCounterValue) int64 {
+	var total int64
+	for _, count := range v.P {
+		total += count
+	}
+	for _, count := range v.N {
+		total -= count
+	}
+	return total
+}
+
+// orSetTag pairs an element with the unique tag its adder minted, the
+// add-wins OR-Set building block: removing an element only removes the
+// tags observed at removal time, so an add concurrent with a remove
+// survives.
+type orSetTag struct {
+	Elem string `json:"elem"`
+	Tag  string `json:"tag"`
+}
+
+// orSetValue is an OR-Set payload: every (element, tag) ever added, and
+// every (element, tag) ever removed. The effective set is Adds minus
+// whatever of Adds also appears in Removes.
+type orSetValue struct {
+	Adds    []orSetTag `json:"adds"`
+	Removes []orSetTag `json:"removes"`
+}
+
+func decodeORSet(v interface{}) orSetValue {
+	switch ov := v.(type) {
+	case orSetValue:
+		return ov
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return orSetValue{}
+		}
+		var decoded orSetValue
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return orSetValue{}
+		}
+		return decoded
+	}
+}
+
+// mergeORSet unions both sides' add-sets and remove-sets, deduplicating
+// by (elem, tag).
+func mergeORSet(a, b interface{}) orSetValue {
+	av, bv := decodeORSet(a), decodeORSet(b)
+	return orSetValue{
+		Adds:    unionTags(av.Adds, bv.Adds),
+		Removes: unionTags(av.Removes, bv.Removes),
+	}
+}
+
+func unionTags(a, b []orSetTag) []orSetTag {
+	seen := make(map[orSetTag]bool, len(a)+len(b))
+	merged := make([]orSetTag, 0, len(a)+len(b))
+	for _, tags := range [][]orSetTag{a, b} {
+		for _, t := range tags {
+			if !seen[t] {
+				seen[t] = true
+				merged = append(merged, t)
+			}
+		}
+	}
+	return merged
+}
+
+// orSetElements reports the effective membership of an OR-Set payload:
+// every added element whose tag hasn't also been removed.
+func orSetElements(v orSetValue) []string {
+	removed := make(map[orSetTag]bool, len(v.Removes))
+	for _, t := range v.Removes {
+		removed[t] = true
+	}
+	elems := make([]string, 0, len(v.Adds))
+	for _, t := range v.Adds {
+		if !removed[t] {
+			elems = append(elems, t.Elem)
+		}
+	}
+	return elems
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrClusterFrozen is returned by Put while the node is frozen.
+var ErrClusterFrozen = errors.New("cluster is frozen: writes are rejected")
+
+// ClusterFreezeResponse summarizes per-node drain state so an operator can
+// wait until the cluster is genuinely quiet before restarts or backups.
+type ClusterFreezeResponse struct {
+	Frozen bool                    `json:"frozen"`
+	Nodes  map[string]NodeDrainInfo `json:"nodes"`
+}
+
+// NodeDrainInfo reports one node's outstanding work at the moment it
+// responded to a freeze/unfreeze propagation.
+type NodeDrainInfo struct {
+	Frozen        bool `json:"frozen"`
+	PendingHints  int  `json:"pending_hints"`
+	MaxClockLag   int  `json:"max_clock_lag"`
+}
+
+// Freeze propagates a freeze/unfreeze request to every node in the ring with
+// one direct HTTP request per node (it does not go through gossip) and
+// collects each node's drain status.
+func (c *Coordinator) Freeze(freeze bool, timeout time.Duration) (*ClusterFreezeResponse, error) {
+	c.mu.Lock()
+	c.frozen = freeze
+	c.mu.Unlock()
+
+	resp := &ClusterFreezeResponse{
+		Frozen: freeze,
+		Nodes:  make(map[string]NodeDrainInfo),
+	}
+
+	resp.Nodes[c.NodeID] = c.localDrainInfo()
+
+	client := newRPCClient(timeout)
+	for _, nodeID := range c.Ring.getAllNodeIDs() {
+		if nodeID == c.NodeID {
+			continue
+		}
+
+		info, err := c.propagateFreeze(client, nodeID, freeze)
+		if err != nil {
+			textLog(c.NodeID, "FREEZE", "Failed to propagate freeze=%v to %s: %v", freeze, nodeID, err)
+			continue
+		}
+		resp.Nodes[nodeID] = *info
+	}
+
+	textLog(c.NodeID, "FREEZE", "Cluster freeze=%v complete, %d nodes reporting", freeze, len(resp.Nodes))
+	return resp, nil
+}
+
+func (c *Coordinator) propagateFreeze(client *http.Client, nodeID string, freeze bool) (*NodeDrainInfo, error) {
+	url := fmt.Sprintf("http://%s:%d/admin/cluster/freeze?freeze=%v", getHost(nodeID), getPortForNode(nodeID), freeze)
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(nil))
+	if err != nil {
+		return nil, err
+	}
+	// Tell the receiver this is a propagated freeze so it only flips its
+	// own flag instead of fanning the request out again.
+	req.Header.Set("X-Dynamo-Internal", "true")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var info NodeDrainInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// localDrainInfo reports this node's own pending-hint count and how far
+// its vector clock has fallen behind the highest value it has observed.
+func (c *Coordinator) localDrainInfo() NodeDrainInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pending := 0
+	for _, hints := range c.Hints {
+		pending += len(hints)
+	}
+
+	return NodeDrainInfo{
+		Frozen:       c.frozen,
+		PendingHints: pending,
+		MaxClockLag:  c.maxClockLag(),
+	}
+}
+
+// maxClockLag is the largest gap between this node's own counter in its
+// local vector clocks and the highest counter it has seen for any peer,
+// a rough proxy for how far replication has fallen behind.
+func (c *Coordinator) maxClockLag() int {
+	maxSeen := make(map[string]int)
+	c.Storage.Iterate(func(k string, v storedValue) bool {
+		if v.VectorClock == nil {
+			return true
+		}
+		for node, count := range v.VectorClock.Clock {
+			if count > maxSeen[node] {
+				maxSeen[node] = count
+			}
+		}
+		return true
+	})
+
+	lag := 0
+	if self, ok := maxSeen[c.NodeID]; ok {
+		for node, count := range maxSeen {
+			if node == c.NodeID {
+				continue
+			}
+			if d := count - self; d > lag {
+				lag = d
+			}
+		}
+	}
+	return lag
+}
+
+// FreezeHandler handles both the operator-facing admin request (which
+// fans the freeze out to the whole cluster) and the internal propagation
+// from a peer coordinator (which only freezes the local node).
+func FreezeHandler(w http.ResponseWriter, r *http.Request) {
+	freeze := r.URL.Query().Get("freeze") == "true"
+	timeout := 5 * time.Second
+	if t := r.URL.Query().Get("timeout"); t != "" {
+		if parsed, err := time.ParseDuration(t); err == nil {
+			timeout = parsed
+		}
+	}
+
+	// Internal propagation from a peer: just flip our own flag and report.
+	if r.Header.Get("X-Dynamo-Internal") == "true" {
+		coordinator.mu.Lock()
+		coordinator.frozen = freeze
+		coordinator.mu.Unlock()
+
+		js, _ := json.Marshal(coordinator.localDrainInfo())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+		return
+	}
+
+	resp, err := coordinator.Freeze(freeze, timeout)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	js, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"math/rand"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -26,14 +29,132 @@ type Member struct {
 	Status    NodeStatus
 	LastSeen  time.Time
 	Metadata  map[string]string // For future extensions
+
+	// Incarnation is SWIM's refutation counter: unlike Heartbeat (which
+	// advances every gossip tick regardless of anything), it only ever
+	// changes when a node explicitly refutes a suspected/down report
+	// about itself (see refuteSuspicion). A report carrying a lower
+	// incarnation than what's already known is stale by definition and
+	// is dropped in updateMember regardless of its heartbeat, which is
+	// what keeps refutation race-free.
+	Incarnation int64
+
+	// KeyCount is the member's last-reported NodeStats.KeyCount, kept
+	// current on gs.Self by the coordinator's statsReporter and
+	// propagated to peers the same way Heartbeat/Status are: carried in
+	// collectMemberStates/applyMemberStates every gossip round. It backs
+	// ConsistentHashRing's bounded-load placement (see LoadProvider).
+	KeyCount int64
 }
 
 type GossipService struct {
 	Self     *Member
 	Members  *sync.Map // map[string]*Member
 	stopChan chan struct{}
+
+	healthMu sync.Mutex
+	health   int // local health score: 0 = healthy, higher = this node itself may be struggling
+
+	// Strategy picks gossip targets each round. Defaults to a
+	// WeightedStrategy; swap it (e.g. UniformStrategy{}) for testing the
+	// selection algorithm in isolation from the network layer.
+	Strategy GossipStrategy
+
+	// Mode negotiates the wire format of /internal/gossip requests this
+	// node sends. Defaults to GossipModePushPull.
+	Mode GossipMode
+
+	lastHeardMu sync.Mutex
+	// LastHeardFrom tracks the highest heartbeat of OUR OWN Self that each
+	// peer has acknowledged back to us (via a push-pull/delta reply),
+	// keyed by peer nodeID. It lets a future round detect whether a given
+	// peer is already caught up on our own state without resending it.
+	LastHeardFrom map[string]int64
+
+	// Broadcasts is the pub-sub plane piggybacked on gossip payloads -
+	// see gossip_broadcast.go.
+	Broadcasts *Broadcast
+
+	// gossipIntervalNs/failureCheckIntervalNs/gossipTimeoutNs hold
+	// time.Duration nanosecond counts behind atomics rather than plain
+	// fields so ConfigWatcher can retune a running node's gossip timing
+	// (see config_watcher.go) from a different goroutine than
+	// gossipLoop/failureDetectionLoop without a lock.
+	gossipIntervalNs       atomic.Int64
+	failureCheckIntervalNs atomic.Int64
+	gossipTimeoutNs        atomic.Int64
+}
+
+// defaultGossipInterval/defaultFailureCheckInterval/defaultGossipTimeout
+// are the historical hardcoded values, tuned for fast local tests; they
+// stay the default until a Config (or a live reload) says otherwise.
+const (
+	defaultGossipInterval       = 500 * time.Millisecond
+	defaultFailureCheckInterval = 1 * time.Second
+	defaultGossipTimeout        = 1 * time.Second
+)
+
+// GossipInterval returns how often gossipLoop currently fires.
+func (gs *GossipService) GossipInterval() time.Duration {
+	return time.Duration(gs.gossipIntervalNs.Load())
+}
+
+// SetGossipInterval retunes gossipLoop's cadence; it takes effect the
+// next time the loop's ticker fires rather than resetting one already in
+// flight.
+func (gs *GossipService) SetGossipInterval(d time.Duration) {
+	gs.gossipIntervalNs.Store(int64(d))
+}
+
+// FailureCheckInterval returns how often failureDetectionLoop currently fires.
+func (gs *GossipService) FailureCheckInterval() time.Duration {
+	return time.Duration(gs.failureCheckIntervalNs.Load())
+}
+
+// SetFailureCheckInterval retunes failureDetectionLoop's cadence, same
+// next-tick semantics as SetGossipInterval.
+func (gs *GossipService) SetFailureCheckInterval(d time.Duration) {
+	gs.failureCheckIntervalNs.Store(int64(d))
+}
+
+// GossipTimeout returns the RPC timeout sendGossipToNode currently uses.
+func (gs *GossipService) GossipTimeout() time.Duration {
+	return time.Duration(gs.gossipTimeoutNs.Load())
 }
 
+// SetGossipTimeout retunes the per-gossip-round RPC timeout.
+func (gs *GossipService) SetGossipTimeout(d time.Duration) {
+	gs.gossipTimeoutNs.Store(int64(d))
+}
+
+// GossipMode selects the wire-level negotiation strategy used for
+// /internal/gossip requests, chosen via the "mode" query parameter.
+type GossipMode string
+
+const (
+	// GossipModeFull is the original protocol: the full membership table
+	// travels in the request body and the receiver just acks "OK". Kept
+	// as the zero value so any caller that doesn't set Mode gets the
+	// historical behavior unchanged.
+	GossipModeFull GossipMode = ""
+	// GossipModePushPull sends the full membership table like
+	// GossipModeFull, but the receiver's response body also carries its
+	// own membership table, so one round trip converges both sides
+	// instead of needing a reply-round from the target later.
+	GossipModePushPull GossipMode = "push-pull"
+	// GossipModeDelta sends a version vector (this node's last-known
+	// heartbeat per member) instead of full member state; the receiver
+	// replies with only the members whose heartbeat has advanced past
+	// what the vector claims, cutting steady-state bandwidth sharply in
+	// a stable cluster.
+	GossipModeDelta GossipMode = "delta"
+)
+
+// maxLocalHealthScore bounds how degraded this node's self-assessment can
+// get, so a long streak of failures can't make suspicion timeouts grow
+// without limit.
+const maxLocalHealthScore = 8
+
 // NewGossipService creates a new gossip service with the given node ID and list of all nodes
 func NewGossipService(nodeID string, allNodes []string) *GossipService {
 	self := &Member{
@@ -46,10 +167,17 @@ func NewGossipService(nodeID string, allNodes []string) *GossipService {
 	}
 
 	gs := &GossipService{
-		Self:     self,
-		Members:  &sync.Map{},
-		stopChan: make(chan struct{}),
+		Self:          self,
+		Members:       &sync.Map{},
+		stopChan:      make(chan struct{}),
+		Strategy:      NewWeightedStrategy(),
+		Mode:          GossipModePushPull,
+		LastHeardFrom: make(map[string]int64),
+		Broadcasts:    NewBroadcast(),
 	}
+	gs.gossipIntervalNs.Store(int64(defaultGossipInterval))
+	gs.failureCheckIntervalNs.Store(int64(defaultFailureCheckInterval))
+	gs.gossipTimeoutNs.Store(int64(defaultGossipTimeout))
 
 	// Initialize member list
 	for _, nid := range allNodes {
@@ -84,10 +212,10 @@ func (gs *GossipService) Stop() {
 
 // Fix for gossipLoop to run more frequently
 func (gs *GossipService) gossipLoop() {
-	ticker := time.NewTicker(500 * time.Millisecond) // Run twice a second for tests
+	ticker := time.NewTicker(gs.GossipInterval())
 	defer ticker.Stop()
 
-	textLog(gs.Self.NodeID, "GOSSIP", "Started gossip loop (2x per second)")
+	textLog(gs.Self.NodeID, "GOSSIP", "Started gossip loop (interval %v)", gs.GossipInterval())
 
 	for {
 		select {
@@ -95,6 +223,7 @@ func (gs *GossipService) gossipLoop() {
 			textLog(gs.Self.NodeID, "GOSSIP", "Sending gossip and incrementing heartbeat")
 			gs.sendGossip()
 			gs.incrementHeartbeat()
+			ticker.Reset(gs.GossipInterval()) // picks up any live reload before the next round
 		case <-gs.stopChan:
 			textLog(gs.Self.NodeID, "GOSSIP", "Stopping gossip loop")
 			return
@@ -104,16 +233,17 @@ func (gs *GossipService) gossipLoop() {
 
 // Fix for failureDetectionLoop to run more frequently
 func (gs *GossipService) failureDetectionLoop() {
-	ticker := time.NewTicker(1 * time.Second) // Run every second for tests
+	ticker := time.NewTicker(gs.FailureCheckInterval())
 	defer ticker.Stop()
 
-	textLog(gs.Self.NodeID, "GOSSIP", "Started failure detection loop")
+	textLog(gs.Self.NodeID, "GOSSIP", "Started failure detection loop (interval %v)", gs.FailureCheckInterval())
 
 	for {
 		select {
 		case <-ticker.C:
 			textLog(gs.Self.NodeID, "GOSSIP", "Checking member statuses")
 			gs.checkMemberStatuses()
+			ticker.Reset(gs.FailureCheckInterval())
 		case <-gs.stopChan:
 			textLog(gs.Self.NodeID, "GOSSIP", "Stopping failure detection loop")
 			return
@@ -126,6 +256,54 @@ func (gs *GossipService) incrementHeartbeat() {
 	gs.Self.LastSeen = time.Now()
 }
 
+// degradeHealth records that an outbound probe from this node failed.
+// Lifeguard's insight is that a failed probe might mean the local node is
+// the one struggling (CPU starvation, GC pause, an overloaded NIC) rather
+// than the target actually being down, so this node should become more
+// lenient with everyone's suspicion timeouts until it recovers.
+func (gs *GossipService) degradeHealth() {
+	gs.healthMu.Lock()
+	defer gs.healthMu.Unlock()
+	if gs.health < maxLocalHealthScore {
+		gs.health++
+	}
+}
+
+// improveHealth records a successful direct probe, letting the local
+// health score decay back toward 0 (fully healthy) over time.
+func (gs *GossipService) improveHealth() {
+	gs.healthMu.Lock()
+	defer gs.healthMu.Unlock()
+	if gs.health > 0 {
+		gs.health--
+	}
+}
+
+// healthMultiplier scales suspicion/failure timeouts up while this node's
+// own health score is degraded, the same dampening Lifeguard's local
+// health awareness applies to avoid declaring peers suspected just
+// because this node was too slow to hear from them.
+func (gs *GossipService) healthMultiplier() float64 {
+	gs.healthMu.Lock()
+	defer gs.healthMu.Unlock()
+	return 1.0 + float64(gs.health)*0.5
+}
+
+// refuteSuspicion bumps this node's own incarnation and immediately
+// rebroadcasts it so the higher incarnation can outrun a stale
+// suspected/down report a peer just sent about us, rather than waiting
+// for the next scheduled gossip tick. Incarnation (not Heartbeat, which
+// advances every tick regardless of refutation) is what every peer's
+// updateMember checks before accepting a status report about us, so a
+// once-refuted suspicion can never resurface from a delayed message.
+func (gs *GossipService) refuteSuspicion(reportedBy string) {
+	gs.Self.Incarnation++
+	gs.incrementHeartbeat()
+	textLogWarn(gs.Self.NodeID, "GOSSIP", "Refuting suspicion reported by %s, incarnation now %d (heartbeat %d)",
+		reportedBy, gs.Self.Incarnation, gs.Self.Heartbeat)
+	go gs.sendGossip()
+}
+
 func (gs *GossipService) sendGossip() {
 	targets := gs.selectGossipTargets(2) // Default fanout of 2
 	for _, target := range targets {
@@ -134,23 +312,20 @@ func (gs *GossipService) sendGossip() {
 }
 
 func (gs *GossipService) selectGossipTargets(fanout int) []*Member {
-	var targets []*Member
+	var candidates []*Member
 	gs.Members.Range(func(key, value interface{}) bool {
 		member := value.(*Member)
 		if member.NodeID != gs.Self.NodeID && member.Status != StatusDown {
-			targets = append(targets, member)
+			candidates = append(candidates, member)
 		}
 		return true
 	})
 
-	// Shuffle and limit to fanout count
-	if len(targets) > fanout {
-		rand.Shuffle(len(targets), func(i, j int) {
-			targets[i], targets[j] = targets[j], targets[i]
-		})
-		return targets[:fanout]
+	strategy := gs.Strategy
+	if strategy == nil {
+		strategy = UniformStrategy{}
 	}
-	return targets
+	return strategy.SelectTargets(gs.Self.NodeID, candidates, fanout)
 }
 
 // Fix for sendGossipToNode to improve reliability
@@ -159,12 +334,20 @@ func (gs *GossipService) sendGossipToNode(target *Member) {
 		return
 	}
 
-	client := &http.Client{
-		Timeout: 1 * time.Second, // Reduced timeout for faster tests
-	}
+	client := newRPCClient(gs.GossipTimeout())
 
+	mode := gs.Mode
 	url := fmt.Sprintf("http://%s:%d/internal/gossip", target.Host, target.Port)
-	payload := gs.createGossipPayload()
+	if mode != GossipModeFull {
+		url = fmt.Sprintf("%s?mode=%s", url, mode)
+	}
+
+	var payload []byte
+	if mode == GossipModeDelta {
+		payload = gs.createDeltaPayload()
+	} else {
+		payload = gs.createGossipPayload()
+	}
 
 	// Make 5 attempts with backoff (up from 3)
 	for retries := 0; retries < 5; retries++ {
@@ -172,15 +355,20 @@ func (gs *GossipService) sendGossipToNode(target *Member) {
 		if err == nil {
 			defer resp.Body.Close()
 			if resp.StatusCode == http.StatusOK {
+				gs.improveHealth()
+				if mode != GossipModeFull {
+					gs.applyReplyBody(resp.Body, target.NodeID)
+				}
 				if target.Status != StatusAlive {
 					textLog(gs.Self.NodeID, "GOSSIP", "Successfully contacted %s, marking as ALIVE", target.NodeID)
 					gs.Members.Store(target.NodeID, &Member{
-						NodeID:    target.NodeID,
-						Host:      target.Host,
-						Port:      target.Port,
-						Status:    StatusAlive,
-						LastSeen:  time.Now(),
-						Heartbeat: target.Heartbeat,
+						NodeID:      target.NodeID,
+						Host:        target.Host,
+						Port:        target.Port,
+						Status:      StatusAlive,
+						LastSeen:    time.Now(),
+						Heartbeat:   target.Heartbeat,
+						Incarnation: target.Incarnation,
 					})
 				}
 				return
@@ -191,107 +379,410 @@ func (gs *GossipService) sendGossipToNode(target *Member) {
 		time.Sleep(time.Duration(100*(1<<uint(retries))) * time.Millisecond)
 	}
 
+	// Before declaring the target suspected, ask a few other members to
+	// probe it on our behalf (SWIM's indirect-probe step). This keeps a
+	// transient problem reaching this node specifically - a one-way
+	// network blip, not the target actually being down - from triggering
+	// a false suspicion the rest of the cluster would otherwise believe.
+	if gs.indirectProbe(target) {
+		textLog(gs.Self.NodeID, "GOSSIP", "Indirect probe via peers confirmed %s is still reachable", target.NodeID)
+		return
+	}
+
+	// We failed to reach target directly and no one else could reach it
+	// either, but a string of failed probes also nudges this node's own
+	// health score - repeated failures to contact anyone are at least as
+	// likely to mean this node's network path is degraded as that every
+	// unresponsive peer really is down.
+	gs.degradeHealth()
+
 	// Mark as suspected after retries fail
-	textLog(gs.Self.NodeID, "GOSSIP", "Failed to contact %s after 5 retries, marking as SUSPECTED", target.NodeID)
+	textLog(gs.Self.NodeID, "GOSSIP", "Failed to contact %s after 5 retries and indirect probing, marking as SUSPECTED", target.NodeID)
 	gs.Members.Store(target.NodeID, &Member{
-		NodeID:    target.NodeID,
-		Host:      target.Host,
-		Port:      target.Port,
-		Status:    StatusSuspected,
-		LastSeen:  target.LastSeen,
-		Heartbeat: target.Heartbeat,
+		NodeID:      target.NodeID,
+		Host:        target.Host,
+		Port:        target.Port,
+		Status:      StatusSuspected,
+		LastSeen:    target.LastSeen,
+		Heartbeat:   target.Heartbeat,
+		Incarnation: target.Incarnation,
 	})
 }
 
-func (gs *GossipService) createGossipPayload() []byte {
-	state := map[string]interface{}{
-		"node_id":   gs.Self.NodeID,
-		"host":      gs.Self.Host,
-		"port":      gs.Self.Port,
-		"heartbeat": gs.Self.Heartbeat,
-		"members":   gs.collectMemberStates(),
+// swimIndirectProbeCount is how many other members are asked to probe a
+// non-responsive target before this node gives up and marks it suspected.
+const swimIndirectProbeCount = 3
+
+// indirectProbe asks up to swimIndirectProbeCount other alive members to
+// attempt to reach target themselves, returning true as soon as any one
+// of them confirms it's reachable.
+func (gs *GossipService) indirectProbe(target *Member) bool {
+	helpers := gs.selectProbeHelpers(target.NodeID, swimIndirectProbeCount)
+	if len(helpers) == 0 {
+		return false
 	}
 
-	payload, _ := json.Marshal(state)
-	return payload
+	results := make(chan bool, len(helpers))
+	for _, helper := range helpers {
+		go func(h *Member) {
+			results <- gs.requestIndirectProbe(h, target)
+		}(helper)
+	}
+
+	for range helpers {
+		if <-results {
+			return true
+		}
+	}
+	return false
 }
 
-func (gs *GossipService) collectMemberStates() map[string]interface{} {
-	members := make(map[string]interface{})
+// selectProbeHelpers picks up to n alive members, excluding the target
+// itself and self, to relay a probe through.
+func (gs *GossipService) selectProbeHelpers(targetNodeID string, n int) []*Member {
+	var candidates []*Member
 	gs.Members.Range(func(key, value interface{}) bool {
 		member := value.(*Member)
-		members[member.NodeID] = map[string]interface{}{
-			"host":      member.Host,
-			"port":      member.Port,
-			"heartbeat": member.Heartbeat,
-			"status":    member.Status,
-			"last_seen": member.LastSeen.UnixNano(),
+		if member.NodeID != targetNodeID && member.NodeID != gs.Self.NodeID && member.Status == StatusAlive {
+			candidates = append(candidates, member)
 		}
 		return true
 	})
-	return members
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
 }
 
-// Fix for HandleGossip to better handle failing nodes
-func (gs *GossipService) HandleGossip(w http.ResponseWriter, r *http.Request) {
-	var payload struct {
-		NodeID    string                 `json:"node_id"`
-		Host      string                 `json:"host"`
-		Port      int                    `json:"port"`
-		Heartbeat int64                  `json:"heartbeat"`
-		Members   map[string]interface{} `json:"members"`
+// requestIndirectProbe asks helper to probe target and reports whether it
+// confirmed the target is reachable.
+func (gs *GossipService) requestIndirectProbe(helper, target *Member) bool {
+	url := fmt.Sprintf("http://%s:%d/internal/gossip/probe", helper.Host, helper.Port)
+	body, _ := json.Marshal(map[string]interface{}{
+		"node_id": target.NodeID,
+		"host":    target.Host,
+		"port":    target.Port,
+	})
+
+	client := newRPCClient(1500 * time.Millisecond)
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false
 	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	var result struct {
+		Alive bool `json:"alive"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false
+	}
+	return result.Alive
+}
+
+// HandleIndirectProbe is the receiving side of indirectProbe: it makes its
+// own direct attempt to reach the named target and reports what it found.
+func (gs *GossipService) HandleIndirectProbe(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		NodeID string `json:"node_id"`
+		Host   string `json:"host"`
+		Port   int    `json:"port"`
+	}
 	defer r.Body.Close()
 
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		textLog(gs.Self.NodeID, "GOSSIP", "Received invalid gossip payload: %v", err)
-		http.Error(w, "Invalid gossip payload", http.StatusBadRequest)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid probe request", http.StatusBadRequest)
 		return
 	}
 
-	// Always mark the sender as alive since we just heard from them
-	gs.updateMember(&Member{
-		NodeID:    payload.NodeID,
-		Host:      payload.Host,
-		Port:      payload.Port,
-		Heartbeat: payload.Heartbeat,
-		LastSeen:  time.Now(),
-		Status:    StatusAlive,
+	alive := gs.pingDirectly(req.Host, req.Port)
+	textLog(gs.Self.NodeID, "GOSSIP", "Indirect probe of %s on behalf of a peer: alive=%v", req.NodeID, alive)
+
+	js, _ := json.Marshal(map[string]bool{"alive": alive})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
+// pingDirectly makes a single short-timeout request to confirm a node is
+// answering; HandleIndirectProbe uses it for probes made on another
+// member's behalf.
+func (gs *GossipService) pingDirectly(host string, port int) bool {
+	url := fmt.Sprintf("http://%s:%d/internal/gossip/ping", host, port)
+	client := newRPCClient(800 * time.Millisecond)
+	resp, err := client.Get(url)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// HandlePing answers a bare liveness check; it carries no payload because
+// indirect probing only needs to know the process is up and accepting
+// connections.
+func (gs *GossipService) HandlePing(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+func (gs *GossipService) createGossipPayload() []byte {
+	state := map[string]interface{}{
+		"node_id":     gs.Self.NodeID,
+		"host":        gs.Self.Host,
+		"port":        gs.Self.Port,
+		"heartbeat":   gs.Self.Heartbeat,
+		"incarnation": gs.Self.Incarnation,
+		"key_count":   gs.Self.KeyCount,
+		"members":     gs.collectMemberStates(),
+		"events":      gs.Broadcasts.Pending(),
+	}
+
+	payload, _ := json.Marshal(state)
+	return payload
+}
+
+// createDeltaPayload builds the GossipModeDelta request body: this node's
+// own identity plus a version vector of the highest heartbeat it has
+// observed for every member (including itself), so the receiver can reply
+// with just what's changed since.
+func (gs *GossipService) createDeltaPayload() []byte {
+	versionVector := make(map[string]int64)
+	gs.Members.Range(func(key, value interface{}) bool {
+		member := value.(*Member)
+		versionVector[member.NodeID] = member.Heartbeat
+		return true
 	})
+	versionVector[gs.Self.NodeID] = gs.Self.Heartbeat
+
+	state := map[string]interface{}{
+		"node_id":        gs.Self.NodeID,
+		"host":           gs.Self.Host,
+		"port":           gs.Self.Port,
+		"heartbeat":      gs.Self.Heartbeat,
+		"incarnation":    gs.Self.Incarnation,
+		"key_count":      gs.Self.KeyCount,
+		"version_vector": versionVector,
+		"events":         gs.Broadcasts.Pending(),
+	}
+
+	payload, _ := json.Marshal(state)
+	return payload
+}
+
+// gossipReply is the shared response body shape for both push-pull and
+// delta replies: the responder's own (possibly filtered) member states,
+// plus any broadcast events it has pending for piggybacking.
+type gossipReply struct {
+	Members map[string]interface{} `json:"members"`
+	Events  []BroadcastEvent       `json:"events"`
+}
+
+// applyReplyBody decodes a push-pull/delta response body and folds it
+// into this node's membership table and broadcast dedupe state the same
+// way HandleGossip folds an incoming request, achieving bidirectional
+// convergence from one round trip instead of waiting for the peer to
+// gossip back on its own schedule.
+func (gs *GossipService) applyReplyBody(body io.Reader, from string) {
+	var reply gossipReply
+	if err := json.NewDecoder(body).Decode(&reply); err != nil {
+		textLog(gs.Self.NodeID, "GOSSIP", "Failed to decode %s reply from %s: %v", gs.Mode, from, err)
+		return
+	}
+	gs.applyMemberStates(reply.Members, from)
+	gs.Broadcasts.Receive(reply.Events)
+
+	gs.lastHeardMu.Lock()
+	gs.LastHeardFrom[from] = gs.Self.Heartbeat
+	gs.lastHeardMu.Unlock()
+}
 
-	// Process remote member states
-	for nodeID, data := range payload.Members {
+// applyMemberStates folds a map of remote member states (as produced by
+// collectMemberStates, carried in either a full gossip request or a
+// push-pull/delta reply) into this node's own membership table.
+func (gs *GossipService) applyMemberStates(members map[string]interface{}, from string) {
+	for nodeID, data := range members {
 		memberData, ok := data.(map[string]interface{})
 		if !ok {
 			textLog(gs.Self.NodeID, "GOSSIP", "Invalid member data format for %s", nodeID)
 			continue
 		}
 
-		// Extract required fields with safety checks
 		host, _ := memberData["host"].(string)
 		portFloat, _ := memberData["port"].(float64)
 		port := int(portFloat)
 		heartbeatFloat, _ := memberData["heartbeat"].(float64)
 		heartbeat := int64(heartbeatFloat)
+		incarnationFloat, _ := memberData["incarnation"].(float64)
+		incarnation := int64(incarnationFloat)
 		statusStr, _ := memberData["status"].(string)
 		lastSeenFloat, _ := memberData["last_seen"].(float64)
+		keyCountFloat, _ := memberData["key_count"].(float64)
+
+		status := NodeStatus(statusStr)
+
+		if nodeID == gs.Self.NodeID && (status == StatusSuspected || status == StatusDown) {
+			gs.refuteSuspicion(from)
+			continue
+		}
 
-		// Create and update member
 		gs.updateMember(&Member{
-			NodeID:    nodeID,
-			Host:      host,
-			Port:      port,
-			Heartbeat: heartbeat,
-			Status:    NodeStatus(statusStr),
-			LastSeen:  time.Unix(0, int64(lastSeenFloat)),
+			NodeID:      nodeID,
+			Host:        host,
+			Port:        port,
+			Heartbeat:   heartbeat,
+			Incarnation: incarnation,
+			Status:      status,
+			LastSeen:    time.Unix(0, int64(lastSeenFloat)),
+			KeyCount:    int64(keyCountFloat),
 		})
 	}
+}
+
+func (gs *GossipService) collectMemberStates() map[string]interface{} {
+	members := make(map[string]interface{})
+	gs.Members.Range(func(key, value interface{}) bool {
+		member := value.(*Member)
+		members[member.NodeID] = map[string]interface{}{
+			"host":        member.Host,
+			"port":        member.Port,
+			"heartbeat":   member.Heartbeat,
+			"incarnation": member.Incarnation,
+			"status":      member.Status,
+			"last_seen":   member.LastSeen.UnixNano(),
+			"key_count":   member.KeyCount,
+		}
+		return true
+	})
+	return members
+}
+
+// Fix for HandleGossip to better handle failing nodes
+func (gs *GossipService) HandleGossip(w http.ResponseWriter, r *http.Request) {
+	mode := GossipMode(r.URL.Query().Get("mode"))
+	defer r.Body.Close()
+
+	if mode == GossipModeDelta {
+		gs.handleDeltaGossip(w, r)
+		return
+	}
+
+	var payload struct {
+		NodeID      string                 `json:"node_id"`
+		Host        string                 `json:"host"`
+		Port        int                    `json:"port"`
+		Heartbeat   int64                  `json:"heartbeat"`
+		Incarnation int64                  `json:"incarnation"`
+		KeyCount    int64                  `json:"key_count"`
+		Members     map[string]interface{} `json:"members"`
+		Events      []BroadcastEvent       `json:"events"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		textLog(gs.Self.NodeID, "GOSSIP", "Received invalid gossip payload: %v", err)
+		http.Error(w, "Invalid gossip payload", http.StatusBadRequest)
+		return
+	}
+
+	// Always mark the sender as alive since we just heard from them
+	gs.updateMember(&Member{
+		NodeID:      payload.NodeID,
+		Host:        payload.Host,
+		Port:        payload.Port,
+		Heartbeat:   payload.Heartbeat,
+		Incarnation: payload.Incarnation,
+		LastSeen:    time.Now(),
+		Status:      StatusAlive,
+		KeyCount:    payload.KeyCount,
+	})
+
+	// A peer believes we're suspected/down - refute it immediately instead
+	// of waiting for our next scheduled heartbeat to eventually outrun
+	// theirs, the way SWIM's refutation works.
+	gs.applyMemberStates(payload.Members, payload.NodeID)
+	gs.Broadcasts.Receive(payload.Events)
+
+	if mode == GossipModePushPull {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gossipReply{
+			Members: gs.collectMemberStates(),
+			Events:  gs.Broadcasts.Pending(),
+		})
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
 
+// handleDeltaGossip answers a GossipModeDelta request: the sender's
+// version vector says what heartbeat it last saw for each member, so the
+// reply only needs to carry members (including us) whose heartbeat has
+// advanced past that - typically a small fraction of the full table in a
+// stable cluster.
+func (gs *GossipService) handleDeltaGossip(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		NodeID        string           `json:"node_id"`
+		Host          string           `json:"host"`
+		Port          int              `json:"port"`
+		Heartbeat     int64            `json:"heartbeat"`
+		Incarnation   int64            `json:"incarnation"`
+		KeyCount      int64            `json:"key_count"`
+		VersionVector map[string]int64 `json:"version_vector"`
+		Events        []BroadcastEvent `json:"events"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		textLog(gs.Self.NodeID, "GOSSIP", "Received invalid delta gossip payload: %v", err)
+		http.Error(w, "Invalid gossip payload", http.StatusBadRequest)
+		return
+	}
+
+	gs.updateMember(&Member{
+		NodeID:      payload.NodeID,
+		Host:        payload.Host,
+		Port:        payload.Port,
+		Heartbeat:   payload.Heartbeat,
+		Incarnation: payload.Incarnation,
+		LastSeen:    time.Now(),
+		Status:      StatusAlive,
+		KeyCount:    payload.KeyCount,
+	})
+	gs.Broadcasts.Receive(payload.Events)
+
+	advanced := make(map[string]interface{})
+	for nodeID, state := range gs.collectMemberStates() {
+		heartbeat := state.(map[string]interface{})["heartbeat"].(int64)
+		if heartbeat > payload.VersionVector[nodeID] {
+			advanced[nodeID] = state
+		}
+	}
+	if gs.Self.Heartbeat > payload.VersionVector[gs.Self.NodeID] {
+		advanced[gs.Self.NodeID] = map[string]interface{}{
+			"host":        gs.Self.Host,
+			"port":        gs.Self.Port,
+			"heartbeat":   gs.Self.Heartbeat,
+			"incarnation": gs.Self.Incarnation,
+			"status":      StatusAlive,
+			"last_seen":   gs.Self.LastSeen.UnixNano(),
+			"key_count":   gs.Self.KeyCount,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gossipReply{
+		Members: advanced,
+		Events:  gs.Broadcasts.Pending(),
+	})
+}
+
 // Fix updateMember function to be more reliable
 func (gs *GossipService) updateMember(newMember *Member) {
 	if newMember == nil || newMember.NodeID == "" {
@@ -315,9 +806,27 @@ func (gs *GossipService) updateMember(newMember *Member) {
 
 	current := existing.(*Member)
 
+	// A report carrying a lower incarnation than what we've already seen
+	// is stale - the node has since refuted whatever suspicion/down report
+	// produced it - so it's dropped outright regardless of its heartbeat.
+	// This is what makes SWIM's refutation race-free: Incarnation only
+	// ever changes via an explicit self-refutation (refuteSuspicion), so
+	// it can't be second-guessed by the ordinary per-tick Heartbeat.
+	if newMember.Incarnation < current.Incarnation {
+		textLog(gs.Self.NodeID, "GOSSIP", "Ignoring stale incarnation %d for %s (current %d)",
+			newMember.Incarnation, newMember.NodeID, current.Incarnation)
+		return
+	}
+	incarnationAdvanced := newMember.Incarnation > current.Incarnation
+	if incarnationAdvanced {
+		textLog(gs.Self.NodeID, "GOSSIP", "Node %s incarnation advanced: %d -> %d",
+			newMember.NodeID, current.Incarnation, newMember.Incarnation)
+		current.Incarnation = newMember.Incarnation
+	}
+
 	// Detect if node was down and is now coming back online
 	if (current.Status == StatusDown || current.Status == StatusSuspected) &&
-		newMember.Heartbeat > current.Heartbeat {
+		(incarnationAdvanced || newMember.Heartbeat > current.Heartbeat) {
 		textLog(gs.Self.NodeID, "GOSSIP", "Node %s has come back ONLINE, reconnecting hints",
 			newMember.NodeID)
 
@@ -331,7 +840,7 @@ func (gs *GossipService) updateMember(newMember *Member) {
 	current.LastSeen = time.Now()
 
 	// Update status based on heartbeat and status information
-	if newMember.Heartbeat > current.Heartbeat {
+	if incarnationAdvanced || newMember.Heartbeat > current.Heartbeat {
 		textLog(gs.Self.NodeID, "GOSSIP", "Node %s heartbeat increased: %d -> %d",
 			newMember.NodeID, current.Heartbeat, newMember.Heartbeat)
 
@@ -346,9 +855,10 @@ func (gs *GossipService) updateMember(newMember *Member) {
 			current.Status = StatusAlive
 		}
 
-		// Always update host/port when heartbeat increases
+		// Always update host/port/key_count when heartbeat increases
 		current.Host = newMember.Host
 		current.Port = newMember.Port
+		current.KeyCount = newMember.KeyCount
 	}
 
 	// Remember that someone else thinks this node is down
@@ -419,8 +929,24 @@ func (gs *GossipService) updateMember(newMember *Member) {
 // Fix for checkMemberStatuses to better detect and handle failures
 func (gs *GossipService) checkMemberStatuses() {
 	now := time.Now()
-	suspicionTimeout := 3 * time.Second // Reduced timeout for tests
-	failureTimeout := 6 * time.Second   // Reduced timeout for tests
+	// Scale the base timeouts by this node's own health multiplier: if
+	// this node itself has been failing probes lately, it's more likely
+	// that's a local problem than that every unresponsive peer is truly
+	// down, so give peers more time before declaring them suspected/down.
+	//
+	// They're also scaled by log(N) of the cluster size, the same
+	// dampening SWIM/Lifeguard use so a larger cluster - where more gossip
+	// hops stand between this node and a quiet one - doesn't false-suspect
+	// peers just because news of their last heartbeat takes longer to
+	// arrive. log(N) is floored at 1 so a 1-2 node cluster keeps the plain
+	// base timeout instead of having it shrunk toward zero.
+	multiplier := gs.healthMultiplier()
+	logFactor := math.Log(float64(gs.clusterSize()))
+	if logFactor < 1 {
+		logFactor = 1
+	}
+	suspicionTimeout := time.Duration(float64(3*time.Second) * multiplier * logFactor) // Reduced timeout for tests
+	failureTimeout := time.Duration(float64(6*time.Second) * multiplier * logFactor)   // Reduced timeout for tests
 
 	textLog(gs.Self.NodeID, "GOSSIP", "Checking member statuses. Current node: %s", gs.Self.NodeID)
 
@@ -524,6 +1050,19 @@ func (gs *GossipService) getClusterState() map[string]interface{} {
 	return state
 }
 
+// clusterSize counts this node plus every member it knows about,
+// including non-ALIVE ones, for feeding Broadcast's transmit-budget
+// formula (which cares about how many hops a message needs to cross, not
+// current liveness).
+func (gs *GossipService) clusterSize() int {
+	size := 1
+	gs.Members.Range(func(key, value interface{}) bool {
+		size++
+		return true
+	})
+	return size
+}
+
 // GetLiveMembers returns all members that are considered alive
 func (gs *GossipService) GetLiveMembers() []*Member {
 	var live []*Member
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"sync"
+)
+
+// BroadcastEvent is a named event piggybacked on top of ordinary gossip
+// traffic, the way memberlist's TransmitLimitedQueue rides user messages
+// on its own gossip rounds. CoalesceKey lets a newer event of the same
+// kind replace an older still-pending one instead of both riding along
+// (e.g. repeated SchemaChange events for the same table).
+type BroadcastEvent struct {
+	UUID        string      `json:"uuid"`
+	Name        string      `json:"name"`
+	CoalesceKey string      `json:"coalesce_key,omitempty"`
+	Payload     interface{} `json:"payload,omitempty"`
+	transmits   int         // remaining times this event may still be piggybacked
+}
+
+// EventHandler is invoked once per newly-seen BroadcastEvent on the
+// receiving side of a gossip exchange.
+type EventHandler func(event BroadcastEvent)
+
+// retransmitMult mirrors memberlist's default RetransmitMult: an event's
+// transmit budget is ceil(log(N+1) * retransmitMult), so larger clusters
+// retransmit proportionally more to make sure everyone sees it.
+const retransmitMult = 4
+
+// broadcastDedupeLRUSize bounds how many recently-seen event UUIDs
+// Broadcast remembers, so a redelivered event (the same hop twice, or two
+// different peers relaying it) isn't dispatched to handlers twice.
+const broadcastDedupeLRUSize = 512
+
+// broadcastMaxPerMessage bounds how many pending events ride along on a
+// single outbound gossip payload, keeping payload size bounded even
+// during a burst of enqueues.
+const broadcastMaxPerMessage = 8
+
+// Broadcast is GossipService's pub-sub plane: callers Enqueue named
+// events, the gossip loop calls Pending to piggyback a bounded batch onto
+// each outbound payload, and the receiving side dedupes by UUID and
+// dispatches to registered handlers. This gives schema changes and
+// hint-replay signals a transport without needing bespoke HTTP endpoints.
+type Broadcast struct {
+	mu       sync.Mutex
+	queue    []*BroadcastEvent
+	handlers map[string][]EventHandler
+
+	seenMu    sync.Mutex
+	seen      map[string]struct{}
+	seenOrder []string
+}
+
+func NewBroadcast() *Broadcast {
+	return &Broadcast{
+		handlers: make(map[string][]EventHandler),
+		seen:     make(map[string]struct{}),
+	}
+}
+
+// Enqueue adds a new event for piggybacking, replacing any still-pending
+// event with the same non-empty CoalesceKey. clusterSize feeds the
+// transmit-budget formula.
+func (b *Broadcast) Enqueue(name, coalesceKey string, payload interface{}, clusterSize int) {
+	event := &BroadcastEvent{
+		UUID:        newEventUUID(),
+		Name:        name,
+		CoalesceKey: coalesceKey,
+		Payload:     payload,
+		transmits:   transmitBudget(clusterSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if coalesceKey != "" {
+		for i, e := range b.queue {
+			if e.CoalesceKey == coalesceKey {
+				b.queue[i] = event
+				return
+			}
+		}
+	}
+	b.queue = append(b.queue, event)
+}
+
+// Pending returns up to broadcastMaxPerMessage events to piggyback on the
+// next outbound gossip payload, decrementing each one's remaining
+// transmit count and dropping any that reach zero.
+func (b *Broadcast) Pending() []BroadcastEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(b.queue)
+	if n > broadcastMaxPerMessage {
+		n = broadcastMaxPerMessage
+	}
+
+	out := make([]BroadcastEvent, 0, n)
+	kept := b.queue[:0]
+	for i, e := range b.queue {
+		if i < n {
+			e.transmits--
+			out = append(out, *e)
+			if e.transmits > 0 {
+				kept = append(kept, e)
+			}
+			continue
+		}
+		kept = append(kept, e)
+	}
+	b.queue = kept
+	return out
+}
+
+// RegisterHandler subscribes fn to every future event named name.
+func (b *Broadcast) RegisterHandler(name string, fn EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], fn)
+}
+
+// Receive dedupes events by UUID against a bounded LRU and dispatches
+// first-seen ones to their registered handlers.
+func (b *Broadcast) Receive(events []BroadcastEvent) {
+	for _, event := range events {
+		if !b.markSeen(event.UUID) {
+			continue
+		}
+
+		b.mu.Lock()
+		handlers := append([]EventHandler(nil), b.handlers[event.Name]...)
+		b.mu.Unlock()
+
+		for _, fn := range handlers {
+			fn(event)
+		}
+	}
+}
+
+// markSeen returns true the first time uuid is observed and false on any
+// later redelivery, evicting the oldest entry once the LRU is full.
+func (b *Broadcast) markSeen(uuid string) bool {
+	b.seenMu.Lock()
+	defer b.seenMu.Unlock()
+
+	if _, ok := b.seen[uuid]; ok {
+		return false
+	}
+	if len(b.seenOrder) >= broadcastDedupeLRUSize {
+		oldest := b.seenOrder[0]
+		b.seenOrder = b.seenOrder[1:]
+		delete(b.seen, oldest)
+	}
+	b.seen[uuid] = struct{}{}
+	b.seenOrder = append(b.seenOrder, uuid)
+	return true
+}
+
+func transmitBudget(clusterSize int) int {
+	if clusterSize < 1 {
+		clusterSize = 1
+	}
+	budget := int(math.Ceil(math.Log(float64(clusterSize+1)) * retransmitMult))
+	if budget < 1 {
+		budget = 1
+	}
+	return budget
+}
+
+func newEventUUID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
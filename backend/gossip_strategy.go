@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GossipStrategy decides which members to gossip to on a given round. It's
+// decoupled from GossipService so the selection algorithm can be swapped
+// (and unit tested) independently of the network machinery in
+// sendGossipToNode.
+type GossipStrategy interface {
+	SelectTargets(self string, candidates []*Member, fanout int) []*Member
+}
+
+// UniformStrategy picks fanout candidates uniformly at random - the
+// original behavior of selectGossipTargets before weighted selection.
+type UniformStrategy struct{}
+
+func (UniformStrategy) SelectTargets(self string, candidates []*Member, fanout int) []*Member {
+	targets := make([]*Member, len(candidates))
+	copy(targets, candidates)
+	rand.Shuffle(len(targets), func(i, j int) {
+		targets[i], targets[j] = targets[j], targets[i]
+	})
+	if len(targets) > fanout {
+		targets = targets[:fanout]
+	}
+	return targets
+}
+
+// suspectedWeightBoost is added, in LastSeen-staleness terms, to any
+// SUSPECTED member's weight so suspicion reports reconverge faster instead
+// of waiting their turn behind long-silent but still-ALIVE peers.
+const suspectedWeightBoost = 5 * time.Second
+
+// recentWindowRounds is the length, counting the round a member is picked
+// in, of the window within which it isn't picked again: it sits out the
+// next recentWindowRounds-1 rounds, so gossip doesn't keep hammering the
+// same peer while others go unheard from.
+const recentWindowRounds = 3
+
+// recentWindowMinMembers is the smallest candidate pool the recency window
+// applies to. Below this, excluding recently-picked peers would starve
+// fanout entirely, so small clusters skip the window and allow repeats.
+const recentWindowMinMembers = recentWindowRounds + 1
+
+// WeightedStrategy biases target selection toward members that have gone
+// the longest without contact (time.Since(LastSeen)), with an extra boost
+// for SUSPECTED members to accelerate reconvergence. A Metadata
+// ["gossip_priority"] value on a Member (parsed as a float multiplier) lets
+// operators bias selection toward specific peers, e.g. preferring
+// cross-rack/cross-DC targets so gossip state crosses failure domains
+// sooner. It also tracks a short recency window so the same peer isn't
+// picked twice in three consecutive rounds unless the cluster is too small
+// for that to leave anyone to pick from.
+type WeightedStrategy struct {
+	mu          sync.Mutex
+	recentPicks map[string]int // nodeID -> rounds remaining before eligible again
+}
+
+func NewWeightedStrategy() *WeightedStrategy {
+	return &WeightedStrategy{recentPicks: make(map[string]int)}
+}
+
+func (ws *WeightedStrategy) SelectTargets(self string, candidates []*Member, fanout int) []*Member {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	for id, rounds := range ws.recentPicks {
+		if rounds <= 1 {
+			delete(ws.recentPicks, id)
+		} else {
+			ws.recentPicks[id] = rounds - 1
+		}
+	}
+
+	pool := candidates
+	if len(candidates) >= recentWindowMinMembers {
+		filtered := make([]*Member, 0, len(candidates))
+		for _, m := range candidates {
+			if ws.recentPicks[m.NodeID] == 0 {
+				filtered = append(filtered, m)
+			}
+		}
+		if len(filtered) > 0 {
+			pool = filtered
+		}
+	}
+
+	weights := make([]float64, len(pool))
+	total := 0.0
+	now := time.Now()
+	for i, m := range pool {
+		w := float64(now.Sub(m.LastSeen))
+		if w <= 0 {
+			w = float64(time.Millisecond)
+		}
+		if m.Status == StatusSuspected {
+			w += float64(suspectedWeightBoost)
+		}
+		if raw, ok := m.Metadata["gossip_priority"]; ok {
+			if boost, err := strconv.ParseFloat(raw, 64); err == nil && boost > 0 {
+				w *= boost
+			}
+		}
+		weights[i] = w
+		total += w
+	}
+
+	picked := make(map[int]bool, fanout)
+	targets := make([]*Member, 0, fanout)
+	for len(targets) < fanout && len(targets) < len(pool) && total > 0 {
+		r := rand.Float64() * total
+		idx := -1
+		for i, w := range weights {
+			if picked[i] {
+				continue
+			}
+			if r < w {
+				idx = i
+				break
+			}
+			r -= w
+		}
+		if idx == -1 {
+			for i := range weights {
+				if !picked[i] {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx == -1 {
+			break
+		}
+		picked[idx] = true
+		total -= weights[idx]
+		targets = append(targets, pool[idx])
+	}
+
+	for _, t := range targets {
+		ws.recentPicks[t.NodeID] = recentWindowRounds
+	}
+
+	return targets
+}
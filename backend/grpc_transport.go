@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"time"
+)
+
+// This file adds a second internal transport alongside the HTTP one in
+// node.go. A real gRPC transport would depend on google.golang.org/grpc
+// plus generated protobuf stubs, but this tree has no go.mod/go.sum to
+// pin that dependency against (every package here is an unversioned
+// `package main` snapshot). Rather than import a library the build can't
+// actually fetch, this uses the standard library's net/rpc, which gives
+// the same shape the request is really after — a binary, connection-
+// oriented RPC path distinct from the JSON-over-HTTP one — without
+// inventing a fake dependency. Swapping the registration in
+// NewGRPCTransportServer/dialTransport for real grpc.Server/grpc.Dial
+// calls is a drop-in change once this repo gains a module manifest.
+
+// TransportPutArgs mirrors the body remotePut sends over HTTP.
+type TransportPutArgs struct {
+	Key         string
+	Value       interface{}
+	VectorClock map[string]int
+	ValueType   ValueType
+}
+
+// TransportPutReply reports whether the local vector-clock comparison
+// accepted the write, same as the HTTP status code remotePut checks.
+type TransportPutReply struct {
+	Accepted bool
+}
+
+// TransportGetArgs mirrors the path parameter InternalGetHandler reads
+// off the URL.
+type TransportGetArgs struct {
+	Key string
+}
+
+// TransportConflict is one sibling of a concurrently-written key, the
+// typed counterpart to the ad hoc `map[string]interface{}{"value":
+// ..., "vector_clock": ...}` entries InternalGetHandler builds for its
+// JSON response body.
+type TransportConflict struct {
+	Value       interface{}
+	VectorClock map[string]int
+}
+
+// TransportGetReply mirrors InternalGetHandler's response body as a
+// typed struct - VectorClock stays map[string]int (the same shape
+// VectorClock.Clock already is) and Conflicts is a slice of
+// TransportConflict, rather than decoding into map[string]interface{}
+// and type-asserting each field back out on the caller's side.
+type TransportGetReply struct {
+	Value       interface{}
+	VectorClock map[string]int
+	ValueType   ValueType
+	Timestamp   time.Time
+	Conflicts   []TransportConflict
+}
+
+// InternalTransport is the net/rpc service registered by the transport
+// server; its methods are the RPC-reachable surface of the Coordinator.
+type InternalTransport struct {
+	coordinator *Coordinator
+}
+
+// PutKV applies a replicated write the same way InternalPutHandler does
+// for a normal (non-force-sync) HTTP PUT.
+func (t *InternalTransport) PutKV(args *TransportPutArgs, reply *TransportPutReply) error {
+	vc := &VectorClock{Clock: args.VectorClock}
+	reply.Accepted = t.coordinator.localPut(args.Key, args.Value, vc, args.ValueType)
+	return nil
+}
+
+// GetKV reads a key the same way InternalGetHandler does over HTTP, but
+// returns TransportGetReply's typed fields instead of a JSON map.
+func (t *InternalTransport) GetKV(args *TransportGetArgs, reply *TransportGetReply) error {
+	value := t.coordinator.localGet(args.Key)
+
+	reply.Value = value.Value
+	reply.VectorClock = value.VectorClock.Clock
+	reply.ValueType = value.ValueType
+	reply.Timestamp = value.Timestamp
+
+	for _, conflict := range value.Conflicts {
+		reply.Conflicts = append(reply.Conflicts, TransportConflict{
+			Value:       conflict.Value,
+			VectorClock: conflict.VectorClock.Clock,
+		})
+	}
+	return nil
+}
+
+// GRPCTransportServer listens for the alternate transport's RPCs. The
+// name matches what operators asked for; see the file comment for why it
+// is backed by net/rpc instead of google.golang.org/grpc.
+type GRPCTransportServer struct {
+	Coordinator *Coordinator
+	Port        int
+	listener    net.Listener
+}
+
+// NewGRPCTransportServer constructs a transport server bound to port.
+func NewGRPCTransportServer(coordinator *Coordinator, port int) *GRPCTransportServer {
+	return &GRPCTransportServer{Coordinator: coordinator, Port: port}
+}
+
+// Start registers the InternalTransport service and accepts connections
+// until the listener is closed.
+func (s *GRPCTransportServer) Start() error {
+	server := rpc.NewServer()
+	if err := server.Register(&InternalTransport{coordinator: s.Coordinator}); err != nil {
+		return fmt.Errorf("registering internal transport: %w", err)
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", s.Port))
+	if err != nil {
+		return fmt.Errorf("listening on port %d: %w", s.Port, err)
+	}
+	s.listener = ln
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.listener == nil {
+				return nil // Stop was called
+			}
+			textLogError(s.Coordinator.NodeID, "TRANSPORT", "internal transport accept error: %v", err)
+			continue
+		}
+		go server.ServeConn(conn)
+	}
+}
+
+// Stop closes the listener, ending Start's accept loop.
+func (s *GRPCTransportServer) Stop() error {
+	ln := s.listener
+	s.listener = nil
+	if ln == nil {
+		return nil
+	}
+	return ln.Close()
+}
+
+// getGRPCPortForNode derives the alternate transport's port from the
+// node's HTTP port the same way getPortForNode derives the HTTP port
+// from the node ID, so no separate peer-port table is needed.
+func getGRPCPortForNode(nodeID string) int {
+	return getPortForNode(nodeID) + 10000
+}
+
+// remotePutViaTransport is the net/rpc counterpart to remotePut, used
+// when the coordinator is configured to prefer the alternate transport
+// for internal replication traffic.
+func (c *Coordinator) remotePutViaTransport(nodeID, key string, value interface{}, vc *VectorClock, valueType ValueType) bool {
+	if vc == nil {
+		vc = NewVectorClock()
+		vc.Increment(c.NodeID)
+	}
+
+	addr := fmt.Sprintf("%s:%d", getHost(nodeID), getGRPCPortForNode(nodeID))
+	client, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		textLog(c.NodeID, "TRANSPORT", "Dial to %s failed: %v", nodeID, err)
+		return false
+	}
+	defer client.Close()
+
+	rpcClient := rpc.NewClient(client)
+	defer rpcClient.Close()
+
+	args := &TransportPutArgs{Key: key, Value: value, VectorClock: vc.Clock, ValueType: valueType}
+	var reply TransportPutReply
+	if err := rpcClient.Call("InternalTransport.PutKV", args, &reply); err != nil {
+		textLog(c.NodeID, "TRANSPORT", "PutKV to %s failed: %v", nodeID, err)
+		return false
+	}
+	return reply.Accepted
+}
+
+// dialTransport opens a net/rpc client to nodeID's alternate transport
+// port, the connection setup remoteGetViaTransport uses
+// (remotePutViaTransport dials inline).
+func dialTransport(nodeID string) (*rpc.Client, error) {
+	addr := fmt.Sprintf("%s:%d", getHost(nodeID), getGRPCPortForNode(nodeID))
+	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.NewClient(conn), nil
+}
+
+// remoteGetViaTransport is the net/rpc counterpart to remoteGet, used
+// when the coordinator is configured to prefer the alternate transport
+// for internal reads. It returns a zero storedValue on any transport
+// failure, matching remoteGet's own not-found/error shape so callers
+// don't need to care which transport served the request.
+func (c *Coordinator) remoteGetViaTransport(nodeID, key string) storedValue {
+	rpcClient, err := dialTransport(nodeID)
+	if err != nil {
+		textLog(c.NodeID, "TRANSPORT", "Dial to %s failed: %v", nodeID, err)
+		return storedValue{}
+	}
+	defer rpcClient.Close()
+
+	args := &TransportGetArgs{Key: key}
+	var reply TransportGetReply
+	if err := rpcClient.Call("InternalTransport.GetKV", args, &reply); err != nil {
+		textLog(c.NodeID, "TRANSPORT", "GetKV from %s failed: %v", nodeID, err)
+		return storedValue{}
+	}
+	if reply.Value == nil {
+		return storedValue{}
+	}
+
+	sv := storedValue{
+		Value:       reply.Value,
+		VectorClock: &VectorClock{Clock: reply.VectorClock},
+		Timestamp:   reply.Timestamp,
+		ValueType:   reply.ValueType,
+	}
+	for _, conflict := range reply.Conflicts {
+		sv.Conflicts = append(sv.Conflicts, storedValue{
+			Value:       conflict.Value,
+			VectorClock: &VectorClock{Clock: conflict.VectorClock},
+		})
+	}
+	return sv
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrLockHeld is returned by AcquireLock when another holder already owns
+// an unexpired lease on the key.
+var ErrLockHeld = errors.New("lock: already held by another owner")
+
+// ErrNotLockOwner is returned by ReleaseLock/RenewLock when the caller's
+// holder ID doesn't match the current lease.
+var ErrNotLockOwner = errors.New("lock: caller does not hold this lease")
+
+// This is a Dynamo-style distributed lock, not a dsync-style one: instead
+// of a dedicated /internal/lock endpoint, a per-peer lock table, and a
+// quorum of acks collected by hand, it's built directly on the Txn CAS
+// path every other conditional write in this package already uses. That
+// gives it the same safety property a dsync lock table would (two
+// coordinators racing AcquireLock can't both observe CompareAbsent/
+// CompareVectorClock succeed, because Txn's compare phase is itself a
+// quorum read), and every mutation - acquire, renew, release - is gated
+// on the lease's vector clock matching what the caller last observed, so
+// a holder whose lease was stolen out from under it (TTL expired, a new
+// holder moved the clock on) can't have a stale renew/release succeed
+// after the fact: the vector clock comparison acts as the fencing token
+// a dsync-style design would persist as a separate monotonic counter.
+// What this intentionally does not attempt is true network-partition
+// testing: every HTTP handler in this codebase (LockHandler included)
+// dispatches through the single package-level `coordinator` var, so
+// there's no way to stand up several independently-addressable nodes
+// inside one test process, and there's no build here to exec as
+// separate processes either. lock_test.go covers mutual exclusion,
+// reacquisition after release, and TTL-based liveness against a
+// single-replica Coordinator instead.
+const lockKeyPrefix = "__lock__:"
+
+// lockLease is the value stored under a lock key.
+type lockLease struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func lockKey(name string) string {
+	return lockKeyPrefix + name
+}
+
+// AcquireLock takes a distributed lock using the Txn CAS path: it only
+// succeeds if the lock key is absent, held by an expired lease, or already
+// held by holder, so two coordinators racing on the same name can't both win.
+func (c *Coordinator) AcquireLock(name, holder string, ttl time.Duration) error {
+	lease := lockLease{Holder: holder, ExpiresAt: time.Now().Add(ttl)}
+	payload, err := json.Marshal(lease)
+	if err != nil {
+		return fmt.Errorf("lock: failed to encode lease: %w", err)
+	}
+
+	existing, current := c.currentLease(name)
+
+	if existing != nil && !existing.ExpiresAt.Before(time.Now()) && existing.Holder != holder {
+		return ErrLockHeld
+	}
+
+	compare := TxnCompare{Key: lockKey(name), Target: CompareAbsent}
+	if current != nil {
+		compare = TxnCompare{Key: lockKey(name), Target: CompareVectorClock, Clock: current}
+	}
+
+	resp, err := c.Txn(TxnRequest{
+		Compares: []TxnCompare{compare},
+		Then:     []TxnOp{{Type: "put", Key: lockKey(name), Value: string(payload)}},
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrLockHeld
+	}
+	return nil
+}
+
+// ReleaseLock drops the lease if held by holder, using the same CAS
+// discipline as AcquireLock so a lease that expired and was re-acquired
+// by someone else can't be torn down out from under them.
+func (c *Coordinator) ReleaseLock(name, holder string) error {
+	existing, current := c.currentLease(name)
+	if existing == nil {
+		return nil // already gone
+	}
+	if existing.Holder != holder {
+		return ErrNotLockOwner
+	}
+
+	resp, err := c.Txn(TxnRequest{
+		Compares: []TxnCompare{{Key: lockKey(name), Target: CompareVectorClock, Clock: current}},
+		Then:     []TxnOp{{Type: "delete", Key: lockKey(name)}},
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrNotLockOwner
+	}
+	return nil
+}
+
+// RenewLock extends an existing lease's TTL, failing if the caller no
+// longer holds it.
+func (c *Coordinator) RenewLock(name, holder string, ttl time.Duration) error {
+	existing, current := c.currentLease(name)
+	if existing == nil || existing.Holder != holder {
+		return ErrNotLockOwner
+	}
+
+	lease := lockLease{Holder: holder, ExpiresAt: time.Now().Add(ttl)}
+	payload, err := json.Marshal(lease)
+	if err != nil {
+		return fmt.Errorf("lock: failed to encode lease: %w", err)
+	}
+
+	resp, err := c.Txn(TxnRequest{
+		Compares: []TxnCompare{{Key: lockKey(name), Target: CompareVectorClock, Clock: current}},
+		Then:     []TxnOp{{Type: "put", Key: lockKey(name), Value: string(payload)}},
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrNotLockOwner
+	}
+	return nil
+}
+
+// currentLease reads back the lease stored for name along with the
+// vector clock snapshot Txn needs for its compare-and-swap.
+func (c *Coordinator) currentLease(name string) (*lockLease, map[string]int) {
+	ctx := ContextWithRequestID(context.Background(), newEventUUID())
+	result, err := c.Get(ctx, lockKey(name))
+	if err != nil || result["value"] == nil || isTombstone(result["value"]) {
+		return nil, nil
+	}
+
+	raw, ok := result["value"].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	var lease lockLease
+	if err := json.Unmarshal([]byte(raw), &lease); err != nil {
+		return nil, nil
+	}
+
+	clock, _ := result["vector_clock"].(map[string]int)
+	return &lease, clock
+}
+
+// LockHandler exposes acquire/release/renew over HTTP so clients that
+// want a simple distributed mutex (leader election, critical sections)
+// don't need to speak the Txn API directly.
+func LockHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Action string `json:"action"` // "acquire", "release", "renew"
+		Name   string `json:"name"`
+		Holder string `json:"holder"`
+		TTLMs  int    `json:"ttl_ms"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	ttl := time.Duration(body.TTLMs) * time.Millisecond
+	if ttl <= 0 {
+		ttl = 10 * time.Second
+	}
+
+	var err error
+	switch body.Action {
+	case "acquire":
+		err = coordinator.AcquireLock(body.Name, body.Holder, ttl)
+	case "release":
+		err = coordinator.ReleaseLock(body.Name, body.Holder)
+	case "renew":
+		err = coordinator.RenewLock(body.Name, body.Holder, ttl)
+	default:
+		http.Error(w, "Unknown lock action", http.StatusBadRequest)
+		return
+	}
+
+	if err == ErrLockHeld || err == ErrNotLockOwner {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -16,8 +17,23 @@ import (
 
 var coordinator *Coordinator
 
-// getPortForNode maps a node ID to its port
+// configWatcher is nil unless the node was started with -config, since
+// there's no source to watch under the legacy CLI-args startup path.
+var configWatcher *ConfigWatcher
+
+// configSource is the ConfigSource configWatcher was built from, kept
+// around only so main can pass the same one into NewConfigWatcher.
+var configSource ConfigSource
+
+// getPortForNode resolves a node ID to its port. The controller's
+// log-replicated membership list is the primary source now that joins
+// register an address; the hardcoded table below only fires before a
+// controller is attached (startup) or for a node it has never seen.
 func getPortForNode(nodeID string) int {
+	if addr, ok := controllerPeerAddr(nodeID); ok {
+		return addr.Port
+	}
+
 	switch nodeID {
 	case "nodeA":
 		return 8000
@@ -44,12 +60,26 @@ func getPortForNode(nodeID string) int {
 	}
 }
 
+// controllerPeerAddr looks up nodeID in the attached Controller's current
+// RingConfig, if one is attached yet.
+func controllerPeerAddr(nodeID string) (PeerAddr, bool) {
+	if coordinator == nil || coordinator.Controller == nil {
+		return PeerAddr{}, false
+	}
+	addr, ok := coordinator.Controller.CurrentConfig().Addrs[nodeID]
+	return addr, ok
+}
+
 func main() {
 	// Parse command line flags
-	configFile := flag.String("config", "", "Path to configuration file")
+	configFile := flag.String("config", "", "Path to configuration file, or a scheme://location URI (file://, yaml://, toml://, etcd://host:port/key); a bare path is treated as file://")
 	nArg := flag.Int("N", 0, "Replication factor")
 	rArg := flag.Int("R", 0, "Read quorum")
 	wArg := flag.Int("W", 0, "Write quorum")
+	respPortArg := flag.Int("resp-port", 0, "Port to serve the RESP (Redis protocol) frontend on, 0 to disable")
+	grpcPortArg := flag.Int("grpc-port", 0, "Port to serve the alternate internal RPC transport on, 0 to disable")
+	useGRPCArg := flag.Bool("use-grpc-transport", false, "Prefer the alternate internal RPC transport over HTTP for replicated writes")
+	conflictPolicyArg := flag.String("conflict-policy", "", "Conflict resolution policy for concurrent writes: merge, lww, or crdt")
 	flag.Parse()
 
 	// Load configuration
@@ -57,8 +87,13 @@ func main() {
 	var err error
 
 	if *configFile != "" {
-		// Load from config file
-		config, err = LoadConfig(*configFile)
+		// Load from the resolved config source (local file, YAML/TOML
+		// file, or a remote etcd key)
+		configSource, err = NewConfigSource(*configFile)
+		if err != nil {
+			log.Fatalf("Failed to resolve config source: %v", err)
+		}
+		config, err = configSource.Load()
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
@@ -103,9 +138,9 @@ func main() {
 			ReplicationFactor:    3,
 			ReadQuorum:           2,
 			WriteQuorum:          2,
-			GossipInterval:       1 * time.Second,
-			FailureCheckInterval: 2 * time.Second,
-			GossipTimeout:        1 * time.Second,
+			GossipInterval:       Duration(1 * time.Second),
+			FailureCheckInterval: Duration(2 * time.Second),
+			GossipTimeout:        Duration(2 * time.Second),
 		}
 	}
 
@@ -119,6 +154,18 @@ func main() {
 	if *wArg > 0 {
 		config.WriteQuorum = *wArg
 	}
+	if *respPortArg > 0 {
+		config.RespPort = *respPortArg
+	}
+	if *grpcPortArg > 0 {
+		config.GRPCPort = *grpcPortArg
+	}
+	if *useGRPCArg {
+		config.UseGRPCTransport = true
+	}
+	if *conflictPolicyArg != "" {
+		config.ConflictPolicy = *conflictPolicyArg
+	}
 
 	// Create logs directory
 	if err := os.MkdirAll("logs", 0755); err != nil {
@@ -136,47 +183,134 @@ func main() {
 	// Add self to ring
 	ring.AddNode(config.NodeID)
 
-	// Add peers to ring
+	// Add peers to ring, and remember where each one is reachable so the
+	// controller can seed its membership list with real addresses instead
+	// of nodes having to guess each other's ports.
 	allNodes := []string{config.NodeID}
+	addrs := map[string]PeerAddr{config.NodeID: {Host: config.Host, Port: config.Port}}
 	for _, peer := range config.Peers {
 		ring.AddNode(peer.NodeID)
 		allNodes = append(allNodes, peer.NodeID)
+		addrs[peer.NodeID] = PeerAddr{Host: peer.Host, Port: peer.Port}
 	}
 
 	// Create a coordinator with configured parameters
-	coordinator = NewCoordinator(config.NodeID, ring, config.ReplicationFactor, config.ReadQuorum, config.WriteQuorum)
+	coordinator = NewCoordinator(config.NodeID, ring, config.ReplicationFactor, config.ReadQuorum, config.WriteQuorum, config.StorageURI)
+	if config.ConflictPolicy != "" {
+		coordinator.SetConflictPolicy(ConflictPolicy(config.ConflictPolicy))
+	}
 
 	// Initialize and start gossip service with node IDs
 	coordinator.Gossip = NewGossipService(config.NodeID, allNodes)
+	if time.Duration(config.GossipInterval) > 0 {
+		coordinator.Gossip.SetGossipInterval(time.Duration(config.GossipInterval))
+	}
+	if time.Duration(config.FailureCheckInterval) > 0 {
+		coordinator.Gossip.SetFailureCheckInterval(time.Duration(config.FailureCheckInterval))
+	}
+	if time.Duration(config.GossipTimeout) > 0 {
+		coordinator.Gossip.SetGossipTimeout(time.Duration(config.GossipTimeout))
+	}
 	coordinator.Gossip.Start()
 
+	// Watch the config source for hot-reloadable changes (gossip timing,
+	// read/write quorum, replication factor within current membership) -
+	// only possible when one was actually given, since the legacy
+	// <node_id> <port> [peers...] startup has no source to watch.
+	if configSource != nil {
+		cw, err := NewConfigWatcher(configSource, config, coordinator)
+		if err != nil {
+			textLogError(coordinator.NodeID, "CONFIG_RELOAD", "failed to start config watcher: %v", err)
+		} else {
+			configWatcher = cw
+			configWatcher.Start()
+		}
+	}
+
+	// Bounded-load ring placement reads each candidate's key count back
+	// out of gossip state; self isn't stored in gs.Members, so it's read
+	// straight off gs.Self instead.
+	coordinator.Ring.SetLoadProvider(func(nodeID string) int64 {
+		if nodeID == coordinator.Gossip.Self.NodeID {
+			return coordinator.Gossip.Self.KeyCount
+		}
+		if m, ok := coordinator.Gossip.Members.Load(nodeID); ok {
+			return m.(*Member).KeyCount
+		}
+		return 0
+	})
+
+	// Replicated membership/shard-assignment controller; joins/leaves are
+	// proposed here instead of being inferred from gossip races, and its
+	// RingConfig.Addrs replaces the old hardcoded nodeID->port table as
+	// the primary way peers resolve each other.
+	controller := NewController(allNodes, addrs, config.ReplicationFactor)
+	coordinator.AttachController(controller)
+
 	// Start periodic tasks (hinted handoff processing)
 	coordinator.startPeriodicTasks()
 
 	// Start anti-entropy process with a faster interval for testing
 	go coordinator.startAntiEntropy(5 * time.Second)
 
+	// Start the RESP frontend so Redis clients can talk to the cluster
+	if config.RespPort > 0 {
+		respServer := NewRESPServer(coordinator, config.RespPort)
+		go func() {
+			if err := respServer.Start(); err != nil {
+				textLogError(coordinator.NodeID, "RESP", "RESP server stopped: %v", err)
+			}
+		}()
+	}
+
+	// Start the alternate internal RPC transport alongside HTTP; see
+	// grpc_transport.go for why it's net/rpc rather than real gRPC.
+	coordinator.useGRPC = config.UseGRPCTransport
+	if config.GRPCPort > 0 {
+		transportServer := NewGRPCTransportServer(coordinator, config.GRPCPort)
+		go func() {
+			if err := transportServer.Start(); err != nil {
+				textLogError(coordinator.NodeID, "TRANSPORT", "internal transport server stopped: %v", err)
+			}
+		}()
+	}
+
 	// Setup HTTP server
 	r := mux.NewRouter()
 
 	// Public endpoints
 	r.HandleFunc("/kv/{key}", GetHandler).Methods("GET")
 	r.HandleFunc("/kv/{key}", PutHandler).Methods("PUT")
+	r.HandleFunc("/kv/{key}/increment", IncrementHandler).Methods("POST")
+	r.HandleFunc("/txn", TxnHandler).Methods("POST")
 
 	// Internal endpoints
 	r.HandleFunc("/internal/kv/{key}", InternalGetHandler).Methods("GET")
 	r.HandleFunc("/internal/kv/{key}", InternalPutHandler).Methods("PUT")
 	r.HandleFunc("/internal/gossip", coordinator.Gossip.HandleGossip)
+	r.HandleFunc("/internal/gossip/probe", coordinator.Gossip.HandleIndirectProbe).Methods("POST")
+	r.HandleFunc("/internal/gossip/ping", coordinator.Gossip.HandlePing).Methods("GET")
 	r.HandleFunc("/internal/merkle/{bucket}", MerkleTreeHandler).Methods("GET")
+	// Operator-facing alias for MerkleTreeHandler: lets an operator
+	// inspect a bucket's tree directly instead of only via the
+	// peer-to-peer anti-entropy exchange on /internal/merkle/{bucket}.
+	r.HandleFunc("/admin/merkle/{bucket}", MerkleTreeHandler).Methods("GET")
+	r.HandleFunc("/internal/bulk-kv", BulkSyncHandler).Methods("POST")
 	r.HandleFunc("/internal/repair/{key}", RepairHandler).Methods("PUT")
 	r.HandleFunc("/internal/store-hint", StoreHintHandler).Methods("POST")
 
 	// Admin endpoints
 	r.HandleFunc("/admin/cluster", ClusterInfoHandler).Methods("GET")
 	r.HandleFunc("/admin/sync", ForceSyncHandler).Methods("POST")
+	r.HandleFunc("/admin/cluster/freeze", FreezeHandler).Methods("POST")
+	r.HandleFunc("/admin/controller", ControllerHandler).Methods("POST")
+	r.HandleFunc("/admin/lock", LockHandler).Methods("POST")
+	r.HandleFunc("/admin/snapshot", SnapshotHandler).Methods("GET")
+	r.HandleFunc("/admin/reload", ReloadConfigHandler).Methods("POST")
+	r.HandleFunc("/metrics", MetricsHandler).Methods("GET")
 
 	addr := fmt.Sprintf(":%d", config.Port)
-	log.Printf("Node %s starting on port %d...", config.NodeID, config.Port)
+	textLog(coordinator.NodeID, "STARTUP", "Node %s starting on port %d...", config.NodeID, config.Port)
 	log.Fatal(http.ListenAndServe(addr, r))
 }
 
@@ -199,35 +333,59 @@ func (c *Coordinator) startAntiEntropy(interval time.Duration) {
 	}
 }
 
+// antiEntropyMaxConcurrentPeers bounds how many peers are Merkle-synced
+// at once. Syncing every peer concurrently with no cap would let a large
+// cluster saturate this node's outbound connections and CPU during a
+// single anti-entropy cycle; this is the back-pressure valve on that.
+const antiEntropyMaxConcurrentPeers = 3
+
 // Fix for performAntiEntropy function - rewrite to be more reliable
 func (c *Coordinator) performAntiEntropy() {
 	peers := c.Ring.getAllNodeIDs()
 	textLog(c.NodeID, "ANTI_ENTROPY", "Starting anti-entropy with peers: %v", peers)
 
+	sem := make(chan struct{}, antiEntropyMaxConcurrentPeers)
+	var wg sync.WaitGroup
+
 	for _, peer := range peers {
 		if peer == c.NodeID {
 			continue
 		}
-		// Directly sync with each peer - more reliable than Merkle trees for test cases
-		textLog(c.NodeID, "ANTI_ENTROPY", "Direct syncing with peer %s", peer)
-		c.directSyncWithNode(peer)
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(peer string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Merkle-tree diff instead of a full keyspace dump: only the
+			// buckets that actually diverged get pushed over the wire,
+			// and in bounded chunks rather than all at once.
+			textLog(c.NodeID, "ANTI_ENTROPY", "Merkle syncing with peer %s", peer)
+			c.merkleSyncWithNode(peer)
+		}(peer)
 	}
 
+	wg.Wait()
+
+	c.Stats.mu.Lock()
+	c.Stats.AntiEntropyRounds++
+	c.Stats.mu.Unlock()
+
 	textLog(c.NodeID, "ANTI_ENTROPY", "Completed anti-entropy cycle")
 }
 
-func logMessage(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	log.Println(message)
-
-	// Ensure the message is also written to our log file in a plain text format
-	// This helps with grep and other text processing tools
-	logFile := fmt.Sprintf("logs/%s.txt", coordinator.NodeID)
-	f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err == nil {
-		defer f.Close()
-		fmt.Fprintln(f, message)
+// requestIDFromRequest returns the correlation ID an upstream caller
+// attached via X-Request-Id (the admin dashboard's controlHandler sets
+// this on the PUT/GET curls it issues for its test scenarios), or mints a
+// fresh one if none was supplied, so a request's full cross-node trail can
+// always be pulled out of logs/<nodeID>.txt by request_id even when the
+// client didn't ask for correlation explicitly.
+func requestIDFromRequest(r *http.Request) string {
+	if id := r.Header.Get("X-Request-Id"); id != "" {
+		return id
 	}
+	return newEventUUID()
 }
 
 // Fix for GetHandler to include better error handling
@@ -235,8 +393,12 @@ func GetHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key := vars["key"]
 
+	reqID := requestIDFromRequest(r)
+	w.Header().Set("X-Request-Id", reqID)
+	ctx := ContextWithRequestID(r.Context(), reqID)
+
 	textLog(coordinator.NodeID, "PUBLIC", "Processing GET request for key %s", key)
-	result, err := coordinator.Get(key)
+	result, err := coordinator.Get(ctx, key)
 	if err != nil {
 		// Special case - try to serve locally if we have it even without quorum
 		localValue := coordinator.localGet(key)
@@ -249,7 +411,7 @@ func GetHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if result["value"] == nil {
+	if result["value"] == nil || isTombstone(result["value"]) {
 		http.Error(w, "Key not found", http.StatusNotFound)
 		return
 	}
@@ -280,16 +442,85 @@ func PutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	valueType := ValueTypeLWW
+	if vt, ok := body["value_type"].(string); ok && vt != "" {
+		valueType = ValueType(vt)
+	}
+
+	reqID := requestIDFromRequest(r)
+	w.Header().Set("X-Request-Id", reqID)
+	ctx := ContextWithRequestID(r.Context(), reqID)
+
 	textLog(coordinator.NodeID, "PUBLIC", "Processing PUT request for key %s", key)
-	err := coordinator.Put(key, value)
+	err := coordinator.PutTyped(ctx, key, value, valueType)
+	if err == ErrClusterFrozen {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := map[string]interface{}{
+		"key":    key,
+		"status": "stored",
+		"node":   coordinator.NodeID,
+	}
+	js, err := json.Marshal(resp)
 	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(append(js, '\n'))
+}
+
+// IncrementHandler applies a G-Counter delta under this node's slot: it
+// reads the key's current counter (if any), folds in +delta for
+// coordinator.NodeID, and writes the result back as a gcounter-typed
+// value so concurrent increments from other nodes converge by per-node
+// max instead of racing under last-writer-wins.
+func IncrementHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+
+	var body struct {
+		Delta int64 `json:"delta"`
+	}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body) // no/empty body just means the default delta below
+	}
+	if body.Delta == 0 {
+		body.Delta = 1
+	}
+
+	reqID := requestIDFromRequest(r)
+	w.Header().Set("X-Request-Id", reqID)
+	ctx := ContextWithRequestID(r.Context(), reqID)
+
+	var current interface{}
+	if existing, err := coordinator.Get(ctx, key); err == nil {
+		current = existing["value"]
+	}
+	counter := BuildGCounterDelta(coordinator.NodeID, current, body.Delta)
+
+	textLog(coordinator.NodeID, "PUBLIC", "Processing INCREMENT request for key %s", key)
+	if err := coordinator.PutTyped(ctx, key, counter, ValueTypeGCounter); err != nil {
+		if err == ErrClusterFrozen {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
 	resp := map[string]interface{}{
 		"key":    key,
 		"status": "stored",
 		"node":   coordinator.NodeID,
+		"value":  counter,
+		"total":  gcounterTotal(counter),
 	}
 	js, err := json.Marshal(resp)
 	if err != nil {
@@ -301,6 +532,65 @@ func PutHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(append(js, '\n'))
 }
 
+// TxnHandler exposes Coordinator.Txn as a single POST endpoint so clients
+// can submit a full If/Then/Else transaction in one request.
+func TxnHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Compares []struct {
+			Key    string         `json:"key"`
+			Target string         `json:"target"`
+			Clock  map[string]int `json:"clock"`
+			Value  interface{}    `json:"value"`
+		} `json:"compares"`
+		Then []struct {
+			Type  string      `json:"type"`
+			Key   string      `json:"key"`
+			Value interface{} `json:"value"`
+		} `json:"then"`
+		Else []struct {
+			Type  string      `json:"type"`
+			Key   string      `json:"key"`
+			Value interface{} `json:"value"`
+		} `json:"else"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	req := TxnRequest{}
+	for _, cmp := range body.Compares {
+		req.Compares = append(req.Compares, TxnCompare{
+			Key:    cmp.Key,
+			Target: TxnCompareTarget(cmp.Target),
+			Clock:  cmp.Clock,
+			Value:  cmp.Value,
+		})
+	}
+	for _, op := range body.Then {
+		req.Then = append(req.Then, TxnOp{Type: op.Type, Key: op.Key, Value: op.Value})
+	}
+	for _, op := range body.Else {
+		req.Else = append(req.Else, TxnOp{Type: op.Type, Key: op.Key, Value: op.Value})
+	}
+
+	resp, err := coordinator.Txn(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	js, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
 // InternalGetHandler handles internal GET requests from other nodes
 func InternalGetHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -311,6 +601,7 @@ func InternalGetHandler(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]interface{}{
 		"value":        value.Value,
 		"vector_clock": value.VectorClock.Clock,
+		"value_type":   string(value.ValueType),
 		"timestamp":    value.Timestamp.Format(time.RFC3339),
 	}
 
@@ -342,6 +633,17 @@ func InternalPutHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key := vars["key"]
 
+	if epochHeader := r.Header.Get("X-Config-Epoch"); epochHeader != "" {
+		if requested, err := strconv.Atoi(epochHeader); err == nil {
+			if current := coordinator.currentConfigEpoch(); requested < current {
+				err := &ErrStaleConfigEpoch{Requested: requested, Current: current}
+				textLog(coordinator.NodeID, "CONTROLLER", "Rejecting write to key %s: %v", key, err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+		}
+	}
+
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -396,12 +698,18 @@ func InternalPutHandler(w http.ResponseWriter, r *http.Request) {
 		textLog(coordinator.NodeID, "INTERNAL", "Request from origin node: %s", originNode)
 	}
 
+	valueType := ValueTypeLWW
+	if vt, ok := body["value_type"].(string); ok && vt != "" {
+		valueType = ValueType(vt)
+	}
+
 	// For any special case, bypass vector clock checks
 	if isForceSync || isHint || isForceKey {
 		sv := storedValue{
 			Value:       value,
 			VectorClock: vc,
 			Timestamp:   time.Now(),
+			ValueType:   valueType,
 		}
 		if err := coordinator.Storage.Put(key, sv); err != nil {
 			textLog(coordinator.NodeID, "ERROR", "Failed to force store key %s: %v", key, err)
@@ -411,7 +719,7 @@ func InternalPutHandler(w http.ResponseWriter, r *http.Request) {
 		textLog(coordinator.NodeID, "INTERNAL", "Force stored key %s from node %s", key, originNode)
 	} else {
 		// Normal put with vector clock comparison
-		coordinator.localPut(key, value, vc)
+		coordinator.localPut(key, value, vc, valueType)
 	}
 
 	// Log the operation
@@ -525,17 +833,7 @@ func MerkleTreeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// build just that bucket's data
-	treeData := make(map[string]interface{})
-	// No lock needed for BadgerDB iteration
-	coordinator.Storage.Iterate(func(k string, v storedValue) bool {
-		if int(hashKey(k))%100 == bucketNum {
-			treeData[k] = v.Value
-		}
-		return true
-	})
-
-	tree := NewMerkleTree(treeData)
+	tree := coordinator.buildBucketTree(bucketNum)
 	serialized := tree.SerializeToMap()
 	js, err := json.Marshal(serialized)
 	if err != nil {
@@ -603,12 +901,15 @@ func RepairHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	valueType, _ := body["value_type"].(string)
+
 	// Create the repaired value
 	sv := storedValue{
 		Value:       value,
 		VectorClock: vc,
 		Conflicts:   conflicts,
 		Timestamp:   time.Now(),
+		ValueType:   ValueType(valueType),
 	}
 
 	// Always store locally without vector clock comparison for repairs
@@ -662,8 +963,10 @@ func StoreHintHandler(w http.ResponseWriter, r *http.Request) {
 	json.Unmarshal(vcBytes, &vcMap)
 	vc := &VectorClock{Clock: vcMap}
 
+	valueType, _ := body["value_type"].(string)
+
 	// Store the hint
-	coordinator.storeHint(targetNode, key, value, vc)
+	coordinator.storeHint(targetNode, key, value, vc, ValueType(valueType))
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
@@ -748,6 +1051,30 @@ func ForceSyncHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Multiple sync cycles started with all nodes"))
 }
 
+// ReloadConfigHandler triggers the same reload path ConfigWatcher's
+// fsnotify loop runs on a config file write, synchronously, and reports
+// which fields were applied live versus rejected as restart-only.
+func ReloadConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if configWatcher == nil {
+		http.Error(w, "config hot-reload is not enabled (node was not started with -config)", http.StatusNotImplemented)
+		return
+	}
+
+	result, err := configWatcher.Reload()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reload failed: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	js, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, "Failed to marshal reload result", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
 // Helper function for anti-entropy with a specific node
 func (c *Coordinator) performAntiEntropyWithNode(nodeID string) {
 	// Skip self and any node that's not considered alive
@@ -756,6 +1083,6 @@ func (c *Coordinator) performAntiEntropyWithNode(nodeID string) {
 		return
 	}
 
-	textLog(c.NodeID, "ANTI_ENTROPY", "Starting anti-entropy (direct sync) with node %s", nodeID)
-	c.directSyncWithNode(nodeID)
+	textLog(c.NodeID, "ANTI_ENTROPY", "Starting anti-entropy (Merkle-tree sync) with node %s", nodeID)
+	c.merkleSyncWithNode(nodeID)
 }
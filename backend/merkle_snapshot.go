@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SnapshotStore persists a MerkleTree's leaf hashes and version to disk so
+// a restart can rehydrate the tree without re-reading and re-hashing
+// every key/value pair in the underlying store - only buildTree's
+// parent-hash combination needs to run again, not hashLeaf over the whole
+// dataset.
+type SnapshotStore interface {
+	Save(tree *MerkleTree) error
+	Load() (*MerkleTree, error)
+}
+
+type merkleSnapshotFile struct {
+	Leaves  []string `json:"leaves"`
+	Keys    []string `json:"keys"`
+	Version int      `json:"version"`
+}
+
+// merkleSnapshotDebounce bounds how often FileSnapshotStore actually
+// touches disk: a burst of Update/Delete calls within this window
+// collapses into a single write of the latest tree state.
+const merkleSnapshotDebounce = 2 * time.Second
+
+// FileSnapshotStore is a WAL-style snapshot store: each flush writes to a
+// temp file and renames it over the target, so a crash mid-write never
+// leaves Load looking at a half-written snapshot.
+type FileSnapshotStore struct {
+	path   string
+	nodeID string // derived from dataDir, for textLog/textLogError - see NewBadgerStorageAtPath
+
+	mu      sync.Mutex
+	pending *MerkleTree
+	timer   *time.Timer
+}
+
+// NewFileSnapshotStore stores the Merkle snapshot alongside the node's
+// other on-disk state under dataDir.
+func NewFileSnapshotStore(dataDir string) *FileSnapshotStore {
+	return &FileSnapshotStore{
+		path:   filepath.Join(dataDir, "merkle_snapshot.json"),
+		nodeID: filepath.Base(dataDir),
+	}
+}
+
+// Save records tree as the latest pending snapshot and arms a debounce
+// timer (if one isn't already running) to flush it to disk after
+// merkleSnapshotDebounce. Callers driving Update/Delete in a hot loop can
+// call Save after every change without hitting disk on every one.
+func (fs *FileSnapshotStore) Save(tree *MerkleTree) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.pending = tree
+	if fs.timer != nil {
+		return nil
+	}
+
+	fs.timer = time.AfterFunc(merkleSnapshotDebounce, func() {
+		fs.mu.Lock()
+		toWrite := fs.pending
+		fs.pending = nil
+		fs.timer = nil
+		fs.mu.Unlock()
+
+		if toWrite == nil {
+			return
+		}
+		if err := fs.flush(toWrite); err != nil {
+			textLogError(fs.nodeID, "MERKLE_SNAPSHOT", "Failed to flush merkle snapshot to %s: %v", fs.path, err)
+		}
+	})
+	return nil
+}
+
+func (fs *FileSnapshotStore) flush(tree *MerkleTree) error {
+	data, err := json.Marshal(merkleSnapshotFile{
+		Leaves:  tree.Leaves,
+		Keys:    tree.Keys,
+		Version: tree.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling merkle snapshot: %w", err)
+	}
+
+	tmp := fs.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing merkle snapshot temp file: %w", err)
+	}
+	return os.Rename(tmp, fs.path)
+}
+
+// Load rehydrates a MerkleTree from the last flushed snapshot, rebuilding
+// Levels and KeyMap/posIndex from the persisted leaf hashes - no access
+// to the original key/value data is needed. Returns an error (wrapping
+// the underlying os.ErrNotExist) if no snapshot has ever been written, so
+// callers know to fall back to a full NewMerkleTree build from storage.
+func (fs *FileSnapshotStore) Load() (*MerkleTree, error) {
+	data, err := os.ReadFile(fs.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap merkleSnapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parsing merkle snapshot: %w", err)
+	}
+
+	mt := &MerkleTree{
+		Leaves:   snap.Leaves,
+		Keys:     snap.Keys,
+		Version:  snap.Version,
+		KeyMap:   make(map[string]string, len(snap.Leaves)),
+		posIndex: make(map[string]int, len(snap.Keys)),
+	}
+	for i, leaf := range mt.Leaves {
+		if i < len(mt.Keys) {
+			mt.KeyMap[leaf] = mt.Keys[i]
+			mt.posIndex[mt.Keys[i]] = i
+		}
+	}
+	mt.buildTree()
+
+	return mt, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const merkleBucketCount = 100
+
+// bulkSyncChunkSize bounds how many diffed keys accumulate before
+// merkleSyncWithNode flushes them to the peer. Sending in bounded chunks
+// instead of one sync-wide batch keeps memory use flat on large
+// divergences and gives the peer back-pressure: the next chunk isn't
+// built until the previous one's RPC has returned.
+const bulkSyncChunkSize = 200
+
+// merkleSyncWithNode reconciles replica state with nodeID using Merkle
+// tree comparison instead of transferring every key: for each bucket, it
+// builds a local tree, fetches the peer's tree for the same bucket, and
+// collects the keys the comparison flags as differing. Diffed keys are
+// flushed to the peer in bounded chunks via bulkSyncToNode rather than
+// one PUT per key or one unbounded batch for the whole sync.
+func (c *Coordinator) merkleSyncWithNode(nodeID string) {
+	if nodeID == c.NodeID {
+		return
+	}
+
+	textLog(c.NodeID, "MERKLE_SYNC", "Starting Merkle-tree sync with %s", nodeID)
+
+	var chunk []BulkSyncItem
+	totalPushed, totalQueued := 0, 0
+
+	flush := func() {
+		if len(chunk) == 0 {
+			return
+		}
+		acked, err := c.bulkSyncToNode(nodeID, chunk)
+		if err != nil {
+			textLog(c.NodeID, "MERKLE_SYNC", "Bulk-sync chunk to %s failed: %v", nodeID, err)
+		}
+		totalPushed += acked
+		totalQueued += len(chunk)
+		chunk = chunk[:0]
+	}
+
+	for bucket := 0; bucket < merkleBucketCount; bucket++ {
+		localTree := c.buildBucketTree(bucket)
+		remoteTree, err := c.fetchRemoteBucketTree(nodeID, bucket)
+		if err != nil {
+			textLog(c.NodeID, "MERKLE_SYNC", "Failed to fetch bucket %d tree from %s: %v", bucket, nodeID, err)
+			continue
+		}
+
+		if localTree.Root() == remoteTree.Root() {
+			continue // buckets already agree, nothing to push
+		}
+
+		diffKeys := localTree.CompareTrees(remoteTree)
+		for _, key := range diffKeys {
+			sv := c.localGet(key)
+			if sv.Value == nil {
+				continue // we don't hold this key locally; the peer owns pushing it back to us
+			}
+			clock := map[string]int{}
+			if sv.VectorClock != nil {
+				clock = sv.VectorClock.Clock
+			}
+			chunk = append(chunk, BulkSyncItem{Key: key, Value: sv.Value, VectorClock: clock, ValueType: sv.ValueType})
+			if len(chunk) >= bulkSyncChunkSize {
+				flush()
+			}
+		}
+	}
+	flush()
+
+	c.Stats.mu.Lock()
+	c.Stats.AntiEntropyRepairs += int64(totalPushed)
+	c.Stats.mu.Unlock()
+
+	textLog(c.NodeID, "MERKLE_SYNC", "Completed Merkle-tree sync with %s: %d/%d keys pushed", nodeID, totalPushed, totalQueued)
+}
+
+// merkleLeafTuple is the (vectorClock, valueHash) half of the Dynamo-style
+// "(key, vectorClock, valueHash)" leaf - key itself is carried separately
+// as the map key passed to NewMerkleTree. Hashing the vector clock in
+// means two replicas holding the same value but with different causal
+// history (e.g. one hasn't seen a concurrent write yet) still show up as
+// a diff, not just a value change.
+type merkleLeafTuple struct {
+	ValueHash string
+	Clock     map[string]int
+}
+
+// buildBucketTree builds (or reuses, via MerkleIndex) the Merkle tree for
+// one bucket of the keyspace, where bucket = hashKey(key) mod
+// merkleBucketCount. Leaves are hashed over (vectorClock, valueHash)
+// rather than the raw value, so a write that only advanced the vector
+// clock still registers as a diff.
+func (c *Coordinator) buildBucketTree(bucket int) *MerkleTree {
+	return c.MerkleIndex.Get(bucket, func() *MerkleTree {
+		data := make(map[string]interface{})
+		c.Storage.Iterate(func(k string, v storedValue) bool {
+			if int(hashKey(k)%merkleBucketCount) == bucket {
+				clock := map[string]int{}
+				if v.VectorClock != nil {
+					clock = v.VectorClock.Clock
+				}
+				data[k] = merkleLeafTuple{
+					ValueHash: hashLeaf(k, v.Value),
+					Clock:     clock,
+				}
+			}
+			return true
+		})
+		return NewMerkleTree(data)
+	})
+}
+
+func (c *Coordinator) fetchRemoteBucketTree(nodeID string, bucket int) (*MerkleTree, error) {
+	url := fmt.Sprintf("http://%s:%d/internal/merkle/%d", getHost(nodeID), getPortForNode(nodeID), bucket)
+
+	client := newRPCClient(requestTimeout)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching merkle bucket %d", resp.StatusCode, bucket)
+	}
+
+	var serialized map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&serialized); err != nil {
+		return nil, err
+	}
+
+	return DeserializeFromMap(serialized)
+}
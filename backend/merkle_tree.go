@@ -9,10 +9,12 @@ import (
 
 // MerkleTree represents a hash tree for efficient data synchronization
 type MerkleTree struct {
-	Leaves  []string          // SHA-256 hashes of the leaf nodes
-	Levels  [][]string        // All levels of the tree, with root at the last level
-	KeyMap  map[string]string // Maps leaf hashes back to keys for lookup
-	Version int               // Tree version for change tracking
+	Leaves   []string          // SHA-256 hashes of the leaf nodes
+	Keys     []string          // Keys in the same order as Leaves (sorted at construction; Update appends new keys at the end), for positional lookup during traversal
+	Levels   [][]string        // All levels of the tree, with root at the last level
+	KeyMap   map[string]string // Maps leaf hashes back to keys for lookup
+	Version  int               // Tree version for change tracking
+	posIndex map[string]int    // key -> index into Leaves/Keys, for O(1) lookup during incremental Update/Delete
 }
 
 func NewMerkleTree(data map[string]interface{}) *MerkleTree {
@@ -26,11 +28,14 @@ func NewMerkleTree(data map[string]interface{}) *MerkleTree {
 	}
 	sort.Strings(keys)
 
-	for _, k := range keys {
+	mt.posIndex = make(map[string]int, len(keys))
+	for i, k := range keys {
 		v := data[k]
 		leafHash := hashLeaf(k, v)
 		mt.Leaves = append(mt.Leaves, leafHash)
+		mt.Keys = append(mt.Keys, k)
 		mt.KeyMap[leafHash] = k
+		mt.posIndex[k] = i
 	}
 
 	mt.buildTree()
@@ -66,6 +71,84 @@ func (mt *MerkleTree) buildTree() {
 	mt.Version++
 }
 
+// deletedLeafHash is the sentinel stored at a leaf position after Delete.
+// It keeps the leaf's position (and therefore every other leaf's
+// position) stable, which is what lets posIndex stay O(1) instead of
+// needing a shift-and-reindex on every delete.
+const deletedLeafHash = "tombstone:0000000000000000000000000000000000000000000000000000000000000000"
+
+// Update recomputes the tree for a single key/value change in O(log N)
+// instead of rebuilding every level from scratch via NewMerkleTree. If key
+// already has a leaf, only its hash and the O(log N) ancestor hashes on
+// the path to the root are touched. If key is new, it's appended as a new
+// leaf and the tree's shape changes - in a stable-sorted-keys tree that
+// shift can't be done incrementally, so this falls back to a full
+// buildTree() for that one case only; the common case this optimizes
+// (values changing on keys that already exist) stays O(log N).
+func (mt *MerkleTree) Update(key string, value interface{}) {
+	leafHash := hashLeaf(key, value)
+
+	if idx, ok := mt.posIndex[key]; ok {
+		delete(mt.KeyMap, mt.Leaves[idx])
+		mt.Leaves[idx] = leafHash
+		mt.KeyMap[leafHash] = key
+		mt.recomputePath(idx)
+		mt.Version++
+		return
+	}
+
+	mt.Leaves = append(mt.Leaves, leafHash)
+	mt.Keys = append(mt.Keys, key)
+	mt.posIndex[key] = len(mt.Leaves) - 1
+	mt.KeyMap[leafHash] = key
+	mt.buildTree()
+}
+
+// Delete tombstones key's leaf rather than removing it from the slice, so
+// every other leaf's position - and therefore posIndex's O(1) lookup
+// guarantee - is preserved. Only the O(log N) ancestor path is
+// recomputed.
+func (mt *MerkleTree) Delete(key string) {
+	idx, ok := mt.posIndex[key]
+	if !ok {
+		return
+	}
+
+	delete(mt.KeyMap, mt.Leaves[idx])
+	delete(mt.posIndex, key)
+	mt.Leaves[idx] = deletedLeafHash
+	mt.recomputePath(idx)
+	mt.Version++
+}
+
+// recomputePath walks from leaf index idx up to the root, recomputing
+// only the hashes on that path - the O(log N) half of Update/Delete.
+func (mt *MerkleTree) recomputePath(idx int) {
+	for level := 0; level < len(mt.Levels)-1; level++ {
+		current := mt.Levels[level]
+		if idx >= len(current) {
+			return
+		}
+
+		siblingIdx := idx ^ 1
+		a, b := current[idx], current[idx]
+		if idx%2 == 0 {
+			if siblingIdx < len(current) {
+				b = current[siblingIdx]
+			}
+		} else {
+			a = current[siblingIdx]
+			b = current[idx]
+		}
+
+		combined := a + b
+		h := sha256.Sum256([]byte(combined))
+		parentIdx := idx / 2
+		mt.Levels[level+1][parentIdx] = hex.EncodeToString(h[:])
+		idx = parentIdx
+	}
+}
+
 func (mt *MerkleTree) Root() string {
 	n := len(mt.Levels)
 	if n == 0 || len(mt.Levels[n-1]) == 0 {
@@ -74,13 +157,78 @@ func (mt *MerkleTree) Root() string {
 	return mt.Levels[n-1][0]
 }
 
+// CompareTrees finds the keys that differ between mt and other. When both
+// trees have the same shape (same height and leaf count - the common case
+// where values changed but the keyset didn't) it walks down from the root,
+// pruning any subtree whose hash already matches instead of touching every
+// leaf. Only the branches that actually diverge get descended into. If the
+// shapes don't match - e.g. a key was added or removed, shifting every
+// later leaf's position - positional comparison can't be trusted, so it
+// falls back to the whole-leaf-set diff, which is shape-independent.
 func (mt *MerkleTree) CompareTrees(other *MerkleTree) []string {
 	if mt.Root() == other.Root() {
 		return nil
 	}
+	if mt.sameShape(other) {
+		return mt.traverseDiff(other)
+	}
 	return mt.findDifferingKeys(other)
 }
 
+func (mt *MerkleTree) sameShape(other *MerkleTree) bool {
+	if len(mt.Levels) != len(other.Levels) || len(mt.Leaves) != len(other.Leaves) {
+		return false
+	}
+	for i := range mt.Levels {
+		if len(mt.Levels[i]) != len(other.Levels[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// traverseDiff performs the hierarchical walk described on CompareTrees,
+// recursing only into child hashes that disagree and recording the keys
+// at any leaf position where the two trees diverge.
+func (mt *MerkleTree) traverseDiff(other *MerkleTree) []string {
+	diffs := map[string]struct{}{}
+
+	var walk func(level, idx int)
+	walk = func(level, idx int) {
+		if idx >= len(mt.Levels[level]) {
+			return
+		}
+		if mt.Levels[level][idx] == other.Levels[level][idx] {
+			return // subtree hashes match, prune without descending further
+		}
+
+		if level == 0 {
+			if idx < len(mt.Keys) {
+				diffs[mt.Keys[idx]] = struct{}{}
+			}
+			if idx < len(other.Keys) {
+				diffs[other.Keys[idx]] = struct{}{}
+			}
+			return
+		}
+
+		left := idx * 2
+		right := left + 1
+		walk(level-1, left)
+		if right < len(mt.Levels[level-1]) {
+			walk(level-1, right)
+		}
+	}
+
+	walk(len(mt.Levels)-1, 0)
+
+	result := make([]string, 0, len(diffs))
+	for k := range diffs {
+		result = append(result, k)
+	}
+	return result
+}
+
 func (mt *MerkleTree) findDifferingKeys(other *MerkleTree) []string {
 	diffs := map[string]struct{}{}
 	otherLeaves := make(map[string]bool, len(other.Leaves))
@@ -119,6 +267,7 @@ func (mt *MerkleTree) SerializeToMap() map[string]interface{} {
 
 	return map[string]interface{}{
 		"leaves":  mt.Leaves,
+		"keys":    mt.Keys,
 		"levels":  mt.Levels,
 		"key_map": km,
 		"version": mt.Version,
@@ -150,6 +299,14 @@ func DeserializeFromMap(data map[string]interface{}) (*MerkleTree, error) {
 		}
 	}
 
+	if keys, ok := data["keys"].([]interface{}); ok {
+		for _, k := range keys {
+			if s, ok := k.(string); ok {
+				mt.Keys = append(mt.Keys, s)
+			}
+		}
+	}
+
 	if levels, ok := data["levels"].([]interface{}); ok {
 		for _, lvl := range levels {
 			if arr, ok := lvl.([]interface{}); ok {
@@ -176,6 +333,11 @@ func DeserializeFromMap(data map[string]interface{}) (*MerkleTree, error) {
 		mt.Version = int(ver)
 	}
 
+	mt.posIndex = make(map[string]int, len(mt.Keys))
+	for i, k := range mt.Keys {
+		mt.posIndex[k] = i
+	}
+
 	return mt, nil
 }
 
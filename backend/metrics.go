@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MetricsHandler renders this node's NodeStats and a couple of gossip
+// cluster gauges as Prometheus text-exposition format. A real deployment
+// would back this with github.com/prometheus/client_golang's Registry and
+// typed Counter/Gauge/Histogram vectors; this hand-rolled writer stands
+// in for that given the repo has no go.mod to pull the dependency from,
+// while still producing output any Prometheus scrape config can consume
+// unchanged. Swap this handler for promhttp.Handler() once the module
+// gains a manifest.
+func MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if coordinator == nil {
+		http.Error(w, "node not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var b strings.Builder
+	writeMetricsSection(&b, "dynamo", coordinator.NodeID, coordinator.Stats.GetSummary())
+
+	if coordinator.Gossip != nil {
+		live := len(coordinator.Gossip.GetLiveMembers())
+		fmt.Fprintf(&b, "# TYPE dynamo_gossip_live_members gauge\n")
+		fmt.Fprintf(&b, "dynamo_gossip_live_members{node=\"%s\"} %d\n", coordinator.NodeID, live)
+	}
+
+	spans := globalTracer.RecentSpans()
+	fmt.Fprintf(&b, "# TYPE dynamo_trace_spans_recorded gauge\n")
+	fmt.Fprintf(&b, "dynamo_trace_spans_recorded{node=\"%s\"} %d\n", coordinator.NodeID, len(spans))
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+// writeMetricsSection flattens the nested map GetSummary returns (grouped
+// under "operations"/"latency"/"features") into individual gauge lines,
+// visiting groups and field names in sorted order so repeated scrapes
+// diff cleanly.
+func writeMetricsSection(b *strings.Builder, namespace, nodeID string, summary map[string]interface{}) {
+	groups := make([]string, 0, len(summary))
+	for g := range summary {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		fields, ok := summary[group].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			value, ok := toFloat64(fields[name])
+			if !ok {
+				continue
+			}
+			metric := fmt.Sprintf("%s_%s_%s", namespace, group, name)
+			fmt.Fprintf(b, "# TYPE %s gauge\n", metric)
+			fmt.Fprintf(b, "%s{node=\"%s\"} %v\n", metric, nodeID, value)
+		}
+	}
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -2,13 +2,14 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,12 +28,17 @@ type storedValue struct {
 	VectorClock *VectorClock
 	Conflicts   []storedValue
 	Timestamp   time.Time
+	// ValueType selects the Merge dispatch concurrent writes use instead
+	// of falling back to Conflicts/last-writer-wins. The zero value
+	// behaves exactly like ValueTypeLWW.
+	ValueType ValueType
 }
 
 type HintedWrite struct {
 	Key         string
 	Value       interface{}
 	VectorClock *VectorClock
+	ValueType   ValueType
 	TargetNode  string
 	Timestamp   time.Time
 	Attempts    int
@@ -46,22 +52,52 @@ type Node struct {
 	Hints       map[string][]HintedWrite
 	Gossip      *GossipService
 	Stats       NodeStats
+	frozen      bool // when true, Put rejects new writes while Get keeps serving
+	Controller  *Controller
+	configEpoch int // latest RingConfig epoch this node has observed
+	storageURI  string
+	useGRPC     bool // prefer the net/rpc transport over HTTP for internal PUTs/GETs
+	conflicts   ConflictResolver
+	MerkleIndex *BucketMerkleIndex
 	mu          sync.RWMutex
 }
 
+// AttachController subscribes the node to a Controller's published
+// RingConfig versions so it can refuse requests tagged with a stale
+// config epoch instead of racing gossip-driven membership changes.
+func (n *Node) AttachController(ctl *Controller) {
+	n.Controller = ctl
+	n.configEpoch = ctl.CurrentConfig().Epoch
+	ctl.Subscribe(func(cfg RingConfig) {
+		n.mu.Lock()
+		n.configEpoch = cfg.Epoch
+		n.mu.Unlock()
+	})
+}
+
+func (n *Node) currentConfigEpoch() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.configEpoch
+}
+
 type Coordinator struct {
 	*Node
 	ReadQuorum  int
 	WriteQuorum int
 }
 
-func NewNode(nodeID string, ring *ConsistentHashRing, replication int) *Node {
+// NewNode constructs a node backed by storage resolved through the
+// package-level storage registry. storageURI may be empty, in which case
+// the registry falls back to badger://data/<nodeID> (the pre-registry
+// default layout); pass memory://<id> to co-locate several logical nodes
+// in one process without disk collisions.
+func NewNode(nodeID string, ring *ConsistentHashRing, replication int, storageURI string) *Node {
 	if replication <= 0 {
 		replication = defaultReplication
 	}
 
-	// Initialize persistent storage
-	storage, err := NewBadgerStorage(nodeID)
+	storage, err := storageRegistry.Get(storageURI, nodeID)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage for node %s: %v", nodeID, err)
 	}
@@ -72,12 +108,29 @@ func NewNode(nodeID string, ring *ConsistentHashRing, replication int) *Node {
 		Replication: replication,
 		Storage:     storage,
 		Hints:       make(map[string][]HintedWrite),
+		storageURI:  storageURI,
+		conflicts:   resolverForPolicy(ConflictPolicyMerge),
+		MerkleIndex: NewBucketMerkleIndex(),
+	}
+
+	if hookable, ok := storage.(interface{ SetWriteHook(func(string)) }); ok {
+		hookable.SetWriteHook(n.MerkleIndex.MarkDirty)
 	}
 
 	return n
 }
 
-func NewCoordinator(nodeID string, ring *ConsistentHashRing, replication, readQ, writeQ int) *Coordinator {
+// Close releases the node's storage handle back to the registry,
+// closing the underlying store once nothing else holds it.
+func (n *Node) Close() error {
+	uri := n.storageURI
+	if uri == "" {
+		uri = fmt.Sprintf("badger://data/%s", n.NodeID)
+	}
+	return storageRegistry.Release(uri)
+}
+
+func NewCoordinator(nodeID string, ring *ConsistentHashRing, replication, readQ, writeQ int, storageURI string) *Coordinator {
 	if readQ <= 0 || writeQ <= 0 || replication <= 0 {
 		log.Fatal("Invalid quorum parameters - all values must be positive integers")
 	}
@@ -87,24 +140,29 @@ func NewCoordinator(nodeID string, ring *ConsistentHashRing, replication, readQ,
 	}
 
 	return &Coordinator{
-		Node:        NewNode(nodeID, ring, replication),
+		Node:        NewNode(nodeID, ring, replication, storageURI),
 		ReadQuorum:  readQ,
 		WriteQuorum: writeQ,
 	}
 }
 
-func (c *Coordinator) Get(key string) (map[string]interface{}, error) {
+func (c *Coordinator) Get(ctx context.Context, key string) (map[string]interface{}, error) {
 	startTime := time.Now()
 	defer c.recordGetLatency(startTime)
 
+	span := globalTracer.StartSpan(c.NodeID, "coordinator.Get")
+	span.SetAttribute("key", key)
+	defer span.End()
+
 	c.Stats.mu.Lock()
 	c.Stats.GetCount++
 	c.Stats.mu.Unlock()
 
-	textLog(c.NodeID, "GET", "Getting key %s", key)
+	structLog(ctx, c.NodeID, LevelInfo, "GET", "Getting key", map[string]interface{}{"key": key})
 
 	nodes, replacements := c.getResponsibleNodes(key, true)
 	responses := c.gatherResponses(nodes, key)
+	span.SetAttribute("responses", len(responses))
 
 	// Try local store as fallback if quorum not met
 	if len(responses) < c.ReadQuorum {
@@ -123,6 +181,7 @@ func (c *Coordinator) Get(key string) (map[string]interface{}, error) {
 	}
 
 	result, conflicts := c.resolveConflicts(responses)
+	span.SetAttribute("conflicts", conflicts)
 
 	// Log resolved result
 	if conflicts > 0 {
@@ -137,14 +196,41 @@ func (c *Coordinator) Get(key string) (map[string]interface{}, error) {
 		go c.handleSloppyReplacements(replacements, responses)
 	}
 
+	structLog(ctx, c.NodeID, LevelInfo, "GET", "Completed key", map[string]interface{}{
+		"key":        key,
+		"conflicts":  conflicts,
+		"latency_ms": time.Since(startTime).Milliseconds(),
+	})
+
 	return c.formatResult(result, conflicts), nil
 }
 
-// Fix for Put method to ensure vector clocks are properly updated
-func (c *Coordinator) Put(key string, value interface{}) error {
+// Put stores value as an opaque, last-writer-wins blob. It's a thin
+// wrapper over PutTyped for callers that don't care about CRDT merge
+// semantics - the overwhelming majority of keys.
+func (c *Coordinator) Put(ctx context.Context, key string, value interface{}) error {
+	return c.PutTyped(ctx, key, value, ValueTypeLWW)
+}
+
+// PutTyped stores value tagged with valueType, which governs how a
+// concurrent write on another replica gets merged back in (see
+// storedValue.Merge). Put is the ValueTypeLWW special case of this.
+func (c *Coordinator) PutTyped(ctx context.Context, key string, value interface{}, valueType ValueType) error {
+	c.mu.RLock()
+	frozen := c.frozen
+	c.mu.RUnlock()
+	if frozen {
+		textLog(c.NodeID, "PUT", "Rejecting write to key %s, cluster is frozen", key)
+		return ErrClusterFrozen
+	}
+
 	startTime := time.Now()
 	defer c.recordPutLatency(startTime)
 
+	span := globalTracer.StartSpan(c.NodeID, "coordinator.Put")
+	span.SetAttribute("key", key)
+	defer span.End()
+
 	c.Stats.mu.Lock()
 	c.Stats.PutCount++
 	c.Stats.mu.Unlock()
@@ -153,16 +239,27 @@ func (c *Coordinator) Put(key string, value interface{}) error {
 	vc := c.updateLocalVectorClock(key)
 	nodes, replacements := c.getResponsibleNodes(key, true)
 
-	textLog(c.NodeID, "PUT", "Putting key %s with value %v to nodes %v (using vector clock %v)",
-		key, value, nodes, vc.Clock)
+	structLog(ctx, c.NodeID, LevelInfo, "PUT", "Putting key", map[string]interface{}{
+		"key":          key,
+		"nodes":        nodes,
+		"vector_clock": vc.Clock,
+	})
 
-	successNodes := c.replicateWrite(nodes, key, value, vc)
+	successNodes := c.replicateWrite(nodes, key, value, vc, valueType)
+	span.SetAttribute("success_nodes", len(successNodes))
 	if len(successNodes) < c.WriteQuorum {
 		c.recordFailedPut()
 		return errors.New("insufficient replicas for write quorum")
 	}
 
-	c.processSloppyReplacements(successNodes, replacements, key, value, vc)
+	c.processSloppyReplacements(successNodes, replacements, key, value, vc, valueType)
+
+	structLog(ctx, c.NodeID, LevelInfo, "PUT", "Completed key", map[string]interface{}{
+		"key":          key,
+		"vector_clock": vc.Clock,
+		"latency_ms":   time.Since(startTime).Milliseconds(),
+	})
+
 	return nil
 }
 
@@ -181,48 +278,41 @@ func (c *Coordinator) getResponsibleNodes(key string, sloppy bool) ([]string, ma
 }
 
 func (c *Coordinator) determineResponsibleNodes(key string, sloppy bool) ([]string, map[string]string) {
-	primary := c.Ring.GetNode(key)
-	allNodes := c.Ring.getAllNodeIDs()
+	totalNodes := len(c.Ring.getAllNodeIDs())
 
 	textLog(c.NodeID, "RESPONSIBILITY", "Determining responsible nodes for key %s", key)
-	textLog(c.NodeID, "RESPONSIBILITY", "All nodes in ring: %v", allNodes)
-	textLog(c.NodeID, "RESPONSIBILITY", "Primary node for key %s: %s", key, primary)
 
-	if len(allNodes) == 0 {
+	if totalNodes == 0 {
 		textLog(c.NodeID, "ERROR", "No nodes available in the ring")
 		return nil, nil
 	}
 
-	primaryIndex := -1
-	for i, n := range allNodes {
-		if n == primary {
-			primaryIndex = i
-			break
-		}
-	}
-	if primaryIndex == -1 {
-		textLog(c.NodeID, "RESPONSIBILITY", "Primary node not found in node list, defaulting to index 0")
-		primaryIndex = 0
+	// preference is the ring's full bounded-load-aware preference order
+	// for key: the first c.Replication entries are the natural replica
+	// set, the rest are the sloppy-quorum fallback pool.
+	preference := c.Ring.GetReplicas(key, totalNodes)
+	textLog(c.NodeID, "RESPONSIBILITY", "Ring preference order for key %s: %v", key, preference)
+
+	natural := c.Replication
+	if natural > len(preference) {
+		natural = len(preference)
 	}
+	pool := preference[natural:]
 
-	nodes := make([]string, 0, c.Replication)
+	nodes := make([]string, 0, natural)
 	replacementMap := make(map[string]string)
 
 	textLog(c.NodeID, "RESPONSIBILITY", "Finding %d replicas with sloppy=%v", c.Replication, sloppy)
 
-	for i := 0; i < c.Replication && i < len(allNodes); i++ {
-		idx := (primaryIndex + i) % len(allNodes)
-		nodeID := allNodes[idx]
+	for i := 0; i < natural; i++ {
+		nodeID := preference[i]
 
 		textLog(c.NodeID, "RESPONSIBILITY", "Checking replica %d: node %s", i, nodeID)
 
 		if sloppy && !c.isNodeAvailable(nodeID) {
 			textLog(c.NodeID, "SLOPPY QUORUM", "Node %s is unavailable, looking for replacement", nodeID)
 
-			for j := 0; j < len(allNodes); j++ {
-				candidateIdx := (primaryIndex + c.Replication + j) % len(allNodes)
-				candidate := allNodes[candidateIdx]
-
+			for _, candidate := range pool {
 				textLog(c.NodeID, "SLOPPY QUORUM", "Considering %s as replacement", candidate)
 
 				if c.isNodeAvailable(candidate) && !contains(nodes, candidate) {
@@ -246,7 +336,7 @@ func (c *Coordinator) determineResponsibleNodes(key string, sloppy bool) ([]stri
 	return nodes, replacementMap
 }
 
-func (c *Coordinator) replicateWrite(nodes []string, key string, value interface{}, vc *VectorClock) []string {
+func (c *Coordinator) replicateWrite(nodes []string, key string, value interface{}, vc *VectorClock, valueType ValueType) []string {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	successNodes := make([]string, 0, len(nodes))
@@ -255,7 +345,7 @@ func (c *Coordinator) replicateWrite(nodes []string, key string, value interface
 		wg.Add(1)
 		go func(nid string) {
 			defer wg.Done()
-			if c.writeToNode(nid, key, value, vc) {
+			if c.writeToNode(nid, key, value, vc, valueType) {
 				mu.Lock()
 				successNodes = append(successNodes, nid)
 				mu.Unlock()
@@ -266,16 +356,22 @@ func (c *Coordinator) replicateWrite(nodes []string, key string, value interface
 	return successNodes
 }
 
-func (c *Coordinator) writeToNode(nodeID, key string, value interface{}, vc *VectorClock) bool {
+func (c *Coordinator) writeToNode(nodeID, key string, value interface{}, vc *VectorClock, valueType ValueType) bool {
 	if nodeID == c.NodeID {
-		return c.localPut(key, value, vc)
+		return c.localPut(key, value, vc, valueType)
 	}
-	return c.remotePutWithRetry(nodeID, key, value, vc)
+	return c.remotePutWithRetry(nodeID, key, value, vc, valueType)
 }
 
-func (c *Coordinator) remotePutWithRetry(nodeID, key string, value interface{}, vc *VectorClock) bool {
+func (c *Coordinator) remotePutWithRetry(nodeID, key string, value interface{}, vc *VectorClock, valueType ValueType) bool {
 	for i := 0; i < maxRetryAttempts; i++ {
-		if c.remotePut(nodeID, key, value, vc) {
+		var ok bool
+		if c.useGRPC {
+			ok = c.remotePutViaTransport(nodeID, key, value, vc, valueType)
+		} else {
+			ok = c.remotePut(nodeID, key, value, vc, valueType)
+		}
+		if ok {
 			return true
 		}
 		time.Sleep(backoffDelay(i))
@@ -283,7 +379,7 @@ func (c *Coordinator) remotePutWithRetry(nodeID, key string, value interface{},
 	return false
 }
 
-func (c *Coordinator) remotePut(nodeID, key string, value interface{}, vc *VectorClock) bool {
+func (c *Coordinator) remotePut(nodeID, key string, value interface{}, vc *VectorClock, valueType ValueType) bool {
 	// Safety check for nil vector clock
 	if vc == nil {
 		vc = NewVectorClock()
@@ -296,6 +392,7 @@ func (c *Coordinator) remotePut(nodeID, key string, value interface{}, vc *Vecto
 	body := map[string]interface{}{
 		"value":        value,
 		"vector_clock": vc.Clock,
+		"value_type":   string(valueType),
 		"timestamp":    time.Now().Format(time.RFC3339),
 	}
 
@@ -307,8 +404,9 @@ func (c *Coordinator) remotePut(nodeID, key string, value interface{}, vc *Vecto
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Config-Epoch", strconv.Itoa(c.currentConfigEpoch()))
 
-	client := &http.Client{Timeout: 5 * time.Second} // Increase timeout for better reliability
+	client := newRPCClient(5 * time.Second) // Increase timeout for better reliability
 	resp, err := client.Do(req)
 	if err != nil {
 		textLog(c.NodeID, "ERROR", "PUT failed to %s: %v", nodeID, err)
@@ -320,7 +418,7 @@ func (c *Coordinator) remotePut(nodeID, key string, value interface{}, vc *Vecto
 }
 
 // Fix for localPut to handle vector clocks correctly
-func (c *Coordinator) localPut(key string, value interface{}, vc *VectorClock) bool {
+func (c *Coordinator) localPut(key string, value interface{}, vc *VectorClock, valueType ValueType) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -337,6 +435,7 @@ func (c *Coordinator) localPut(key string, value interface{}, vc *VectorClock) b
 		Value:       value,
 		VectorClock: vc.Clone(),
 		Timestamp:   time.Now(),
+		ValueType:   valueType,
 	}
 
 	if existing, exists := c.Storage.Get(key); exists {
@@ -346,6 +445,18 @@ func (c *Coordinator) localPut(key string, value interface{}, vc *VectorClock) b
 
 		switch comparison {
 		case "concurrent":
+			if valueType.isCRDT() && existing.ValueType == valueType && vc.Compare(existing.VectorClock) == 0 {
+				c.Stats.mu.Lock()
+				c.Stats.CRDTMergesCount++
+				c.Stats.mu.Unlock()
+
+				newValue = existing.Merge(newValue)
+
+				textLog(c.NodeID, "CRDT_MERGE", "Merged concurrent %s writes for key %s, vector clock: %v",
+					valueType, key, newValue.VectorClock.Clock)
+				break
+			}
+
 			c.Stats.mu.Lock()
 			c.Stats.ConflictsDetected++
 			c.Stats.mu.Unlock()
@@ -443,7 +554,13 @@ func (c *Coordinator) retrieveValue(nodeID, key string) storedValue {
 
 func (c *Coordinator) remoteGetWithRetry(nodeID, key string) storedValue {
 	for i := 0; i < maxRetryAttempts; i++ {
-		if sv := c.remoteGet(nodeID, key); sv.Value != nil {
+		var sv storedValue
+		if c.useGRPC {
+			sv = c.remoteGetViaTransport(nodeID, key)
+		} else {
+			sv = c.remoteGet(nodeID, key)
+		}
+		if sv.Value != nil {
 			return sv
 		}
 		time.Sleep(backoffDelay(i))
@@ -456,10 +573,10 @@ func (c *Coordinator) remoteGet(nodeID, key string) storedValue {
 		getHost(nodeID), getPortForNode(nodeID), key)
 
 	req, _ := http.NewRequest("GET", url, nil)
-	client := &http.Client{Timeout: requestTimeout}
+	client := newRPCClient(requestTimeout)
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("GET failed from %s: %v", nodeID, err)
+		textLogWarn(c.NodeID, "GET", "GET failed from %s: %v", nodeID, err)
 		return storedValue{}
 	}
 	defer resp.Body.Close()
@@ -497,6 +614,7 @@ func (c *Coordinator) localGet(key string) storedValue {
 					Value:       hint.Value,
 					VectorClock: hint.VectorClock,
 					Timestamp:   hint.Timestamp,
+					ValueType:   hint.ValueType,
 				}
 			}
 		}
@@ -521,10 +639,13 @@ func parseStoredValue(data map[string]interface{}) storedValue {
 		}
 	}
 
+	valueType, _ := data["value_type"].(string)
+
 	return storedValue{
 		Value:       data["value"],
 		VectorClock: &VectorClock{Clock: vcMap},
 		Timestamp:   timestamp,
+		ValueType:   ValueType(valueType),
 	}
 }
 
@@ -543,7 +664,14 @@ func (c *Coordinator) resolveConflicts(responses map[string]storedValue) (stored
 		switch comparison {
 		case "concurrent":
 			conflictCount++
-			current = c.mergeConflicts(current, sv)
+			if current.ValueType.isCRDT() && sv.ValueType == current.ValueType && current.VectorClock.Compare(sv.VectorClock) == 0 {
+				c.Stats.mu.Lock()
+				c.Stats.CRDTMergesCount++
+				c.Stats.mu.Unlock()
+				current = current.Merge(sv)
+			} else {
+				current = c.conflicts.Resolve(current, sv)
+			}
 		case "newer":
 			current = sv
 		}
@@ -551,13 +679,13 @@ func (c *Coordinator) resolveConflicts(responses map[string]storedValue) (stored
 	return current, conflictCount
 }
 
-func (c *Coordinator) mergeConflicts(a, b storedValue) storedValue {
-	merged := a
-	merged.VectorClock = a.VectorClock.Clone()
-	merged.VectorClock.Merge(b.VectorClock)
-	merged.Conflicts = append(a.Conflicts, b)
-	merged.Timestamp = time.Now()
-	return merged
+// SetConflictPolicy swaps the resolver used for concurrent writes found
+// during Get's quorum comparison. Defaults to ConflictPolicyMerge, which
+// matches the resolver this node was constructed with.
+func (c *Coordinator) SetConflictPolicy(policy ConflictPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conflicts = resolverForPolicy(policy)
 }
 
 func (c *Coordinator) performReadRepairs(nodes []string, key string, latest storedValue) {
@@ -575,6 +703,7 @@ func (c *Coordinator) repairNode(nodeID, key string, value storedValue) {
 	body := map[string]interface{}{
 		"value":        value.Value,
 		"vector_clock": value.VectorClock.Clock,
+		"value_type":   string(value.ValueType),
 		"timestamp":    value.Timestamp.Format(time.RFC3339),
 	}
 
@@ -594,7 +723,7 @@ func (c *Coordinator) repairNode(nodeID, key string, value storedValue) {
 	req, _ := http.NewRequest("PUT", url, bytes.NewReader(bodyBytes))
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: requestTimeout}
+	client := newRPCClient(requestTimeout)
 	resp, err := client.Do(req)
 	if err == nil && resp.StatusCode == http.StatusOK {
 		c.Stats.mu.Lock()
@@ -612,6 +741,7 @@ func (c *Coordinator) formatResult(value storedValue, conflicts int) map[string]
 	result := map[string]interface{}{
 		"value":        value.Value,
 		"vector_clock": value.VectorClock.Clock,
+		"value_type":   string(value.ValueType),
 	}
 
 	if conflicts > 0 {
@@ -677,7 +807,14 @@ func contains(nodes []string, nodeID string) bool {
 	return false
 }
 
+// getHost resolves a node ID to its reachable hostname. Like
+// getPortForNode, the controller's membership list is checked first; the
+// "-"-suffix parsing below only applies before a controller is attached.
 func getHost(nodeID string) string {
+	if addr, ok := controllerPeerAddr(nodeID); ok && addr.Host != "" {
+		return addr.Host
+	}
+
 	parts := strings.Split(nodeID, "-")
 	if len(parts) > 1 {
 		return strings.Join(parts[:len(parts)-1], "-")
@@ -744,17 +881,17 @@ func (c *Coordinator) handleSloppyReplacements(replacements map[string]string, r
 				})
 
 				if keyValue == "" {
-					log.Printf("Unable to determine key for hinted handoff")
+					textLogWarn(c.NodeID, "HINTED_HANDOFF", "Unable to determine key for hinted handoff")
 					continue
 				}
 			}
 
-			c.storeHint(original, keyValue, sv.Value, sv.VectorClock)
+			c.storeHint(original, keyValue, sv.Value, sv.VectorClock, sv.ValueType)
 		}
 	}
 }
 
-func (c *Coordinator) storeHint(targetNode, key string, value interface{}, vc *VectorClock) {
+func (c *Coordinator) storeHint(targetNode, key string, value interface{}, vc *VectorClock, valueType ValueType) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -772,6 +909,7 @@ func (c *Coordinator) storeHint(targetNode, key string, value interface{}, vc *V
 		Key:         key,
 		Value:       value,
 		VectorClock: vc.Clone(),
+		ValueType:   valueType,
 		TargetNode:  targetNode,
 		Timestamp:   time.Now(),
 		Attempts:    0,
@@ -791,7 +929,7 @@ func (c *Coordinator) storeHint(targetNode, key string, value interface{}, vc *V
 	c.Stats.mu.Unlock()
 }
 
-func (c *Coordinator) processSloppyReplacements(successNodes []string, replacements map[string]string, key string, value interface{}, vc *VectorClock) {
+func (c *Coordinator) processSloppyReplacements(successNodes []string, replacements map[string]string, key string, value interface{}, vc *VectorClock, valueType ValueType) {
 	if len(replacements) > 0 {
 		textLog(c.NodeID, "SLOPPY QUORUM", "Processing replacements for key %s: %v", key, replacements)
 
@@ -803,7 +941,7 @@ func (c *Coordinator) processSloppyReplacements(successNodes []string, replaceme
 			if contains(successNodes, replacement) {
 				textLog(c.NodeID, "HINT STORAGE", "Will store hint on %s for unavailable node %s",
 					replacement, original)
-				go c.storeHint(original, key, value, vc)
+				go c.storeHint(original, key, value, vc, valueType)
 			}
 		}
 	}
@@ -913,6 +1051,7 @@ func (c *Coordinator) deliverHintDirect(hint HintedWrite) bool {
 	body := map[string]interface{}{
 		"value":        hint.Value,
 		"vector_clock": hint.VectorClock.Clock,
+		"value_type":   string(hint.ValueType),
 		"timestamp":    hint.Timestamp.Format(time.RFC3339),
 		"is_hint":      true,
 		"origin_node":  c.NodeID,
@@ -931,7 +1070,7 @@ func (c *Coordinator) deliverHintDirect(hint HintedWrite) bool {
 
 		req.Header.Set("Content-Type", "application/json")
 
-		client := &http.Client{Timeout: 5 * time.Second} // Longer timeout
+		client := newRPCClient(5 * time.Second) // Longer timeout
 		resp, err := client.Do(req)
 
 		if err == nil {
@@ -958,6 +1097,16 @@ func (c *Coordinator) deliverHintDirect(hint HintedWrite) bool {
 func (c *Coordinator) forceReconnectHints(nodeID string) {
 	textLog(c.NodeID, "HINT_DELIVERY", "Force reconnecting hints for node %s", nodeID)
 
+	// Take the distributed lock for this node's hint queue so two
+	// coordinators that both notice nodeID come back don't redeliver (and
+	// race to remove) the same hints concurrently.
+	lockName := "hints:" + nodeID
+	if err := c.AcquireLock(lockName, c.NodeID, 30*time.Second); err != nil {
+		textLog(c.NodeID, "HINT_DELIVERY", "Skipping forced reconnect for node %s, lock held elsewhere: %v", nodeID, err)
+		return
+	}
+	defer c.ReleaseLock(lockName, c.NodeID)
+
 	c.mu.RLock()
 	if hints, exists := c.Hints[nodeID]; exists && len(hints) > 0 {
 		// Make a copy to avoid holding the lock
@@ -1011,7 +1160,7 @@ func (c *Coordinator) forceReconnectHints(nodeID string) {
 		if c.Ring.GetNode(key) == nodeID {
 			// Found a key that belongs to the target node
 			// Send it in a separate goroutine to avoid blocking iteration
-			go c.remotePutWithRetry(nodeID, key, value.Value, value.VectorClock)
+			go c.remotePutWithRetry(nodeID, key, value.Value, value.VectorClock, value.ValueType)
 		}
 		return true
 	})
@@ -1054,7 +1203,7 @@ func (c *Coordinator) forceReplicateKeyToNode(key string, targetNodeID string) b
 		}
 
 		req.Header.Set("Content-Type", "application/json")
-		client := &http.Client{Timeout: 2 * time.Second}
+		client := newRPCClient(2 * time.Second)
 		resp, err := client.Do(req)
 
 		if err == nil {
@@ -1088,6 +1237,7 @@ func (c *Coordinator) deliverHint(hint HintedWrite) bool {
 	body := map[string]interface{}{
 		"value":        hint.Value,
 		"vector_clock": hint.VectorClock.Clock,
+		"value_type":   string(hint.ValueType),
 		"timestamp":    hint.Timestamp.Format(time.RFC3339),
 		"is_hint":      true,
 		"origin_node":  c.NodeID,
@@ -1106,7 +1256,7 @@ func (c *Coordinator) deliverHint(hint HintedWrite) bool {
 
 		req.Header.Set("Content-Type", "application/json")
 
-		client := &http.Client{Timeout: 5 * time.Second} // Longer timeout
+		client := newRPCClient(5 * time.Second) // Longer timeout
 		resp, err := client.Do(req)
 
 		if err == nil {
@@ -1129,6 +1279,10 @@ func (c *Coordinator) deliverHint(hint HintedWrite) bool {
 	return false
 }
 
+// directSyncWithNode dumps the full local keyspace to nodeID. The periodic
+// anti-entropy loop now uses merkleSyncWithNode instead; this is kept
+// around as a manual escape hatch for debugging divergence that a Merkle
+// comparison doesn't explain.
 func (c *Coordinator) directSyncWithNode(nodeID string) {
 	// Skip self
 	if nodeID == c.NodeID {
@@ -1210,7 +1364,7 @@ func (c *Coordinator) forceSyncKey(nodeID, key string, value interface{}, vc *Ve
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := newRPCClient(5 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		textLog(c.NodeID, "ANTI_ENTROPY", "Error syncing key %s to %s: %v", key, nodeID, err)
@@ -1228,11 +1382,24 @@ func (c *Coordinator) statsReporter() {
 	for {
 		select {
 		case <-ticker.C:
+			c.refreshLoadStats()
 			c.logStats()
 		}
 	}
 }
 
+// refreshLoadStats recomputes this node's key count and publishes it onto
+// gs.Self.KeyCount so the next gossip round carries it to every peer -
+// the data ConsistentHashRing's bounded-load placement reads back out via
+// its LoadProvider.
+func (c *Coordinator) refreshLoadStats() {
+	keyCount, dataSize := EstimateDataSize(c)
+	c.Stats.UpdateStorageStats(keyCount, dataSize)
+	if c.Gossip != nil {
+		c.Gossip.Self.KeyCount = keyCount
+	}
+}
+
 func (c *Coordinator) logStats() {
 	c.Stats.mu.Lock()
 	defer c.Stats.mu.Unlock()
@@ -1248,36 +1415,16 @@ func (c *Coordinator) logStats() {
 		putSuccessfulOps = 1
 	}
 
-	log.Printf("Node Stats:")
-	log.Printf("  Operations: GET(%d/%d) PUT(%d/%d)",
+	textLog(c.NodeID, "STATS", "Operations: GET(%d/%d) PUT(%d/%d)",
 		c.Stats.SuccessfulGets, c.Stats.GetCount,
 		c.Stats.SuccessfulPuts, c.Stats.PutCount)
-	log.Printf("  Latency: GET[avg:%dms max:%dms] PUT[avg:%dms max:%dms]",
+	textLog(c.NodeID, "STATS", "Latency: GET[avg:%dms max:%dms] PUT[avg:%dms max:%dms]",
 		c.Stats.TotalGetLatency/getSuccessfulOps,
 		c.Stats.MaxGetLatency,
 		c.Stats.TotalPutLatency/putSuccessfulOps,
 		c.Stats.MaxPutLatency)
-	log.Printf("  Conflicts: detected:%d resolved:%d",
+	textLog(c.NodeID, "STATS", "Conflicts: detected:%d resolved:%d",
 		c.Stats.ConflictsDetected, c.Stats.ConflictsResolved)
-	log.Printf("  Hints: stored:%d delivered:%d",
+	textLog(c.NodeID, "STATS", "Hints: stored:%d delivered:%d",
 		c.Stats.HintStoreCount, c.Stats.HintDeliverCount)
 }
-
-func textLog(nodeID, category, format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	formatted := fmt.Sprintf("[%s] %s: %s",
-		time.Now().Format("2006-01-02 15:04:05"),
-		category,
-		message)
-
-	// Ensure logs directory exists
-	os.MkdirAll("logs", 0755)
-
-	// Write to a text file with the node's ID
-	logFile := fmt.Sprintf("logs/%s.txt", nodeID)
-	f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err == nil {
-		defer f.Close()
-		fmt.Fprintln(f, formatted)
-	}
-}
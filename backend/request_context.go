@@ -0,0 +1,25 @@
+package main
+
+import "context"
+
+type ctxKey int
+
+const requestIDCtxKey ctxKey = iota
+
+// ContextWithRequestID returns a copy of ctx carrying id as the request's
+// correlation ID. HTTP handlers set this once per inbound request (minting
+// a new ID, or reusing one an upstream caller already attached via the
+// X-Request-Id header) so every structLog call made with that ctx (the
+// coordinator's GET/PUT entry and completion lines) can be pulled back out
+// of each node's log file with one filter. Replication, read repair and
+// anti-entropy log through textLog and do not carry the ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey, id)
+}
+
+// RequestIDFromContext returns the correlation ID stashed by
+// ContextWithRequestID, or "" if ctx carries none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey).(string)
+	return id
+}
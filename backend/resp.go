@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RESPServer accepts a subset of the Redis wire protocol (RESP2) and
+// translates commands into Coordinator Get/Put calls, so existing Redis
+// clients (redis-cli, go-redis, ...) can talk to the cluster directly.
+type RESPServer struct {
+	Coordinator *Coordinator
+	Port        int
+	listener    net.Listener
+}
+
+// NewRESPServer creates a RESP frontend bound to the given port.
+func NewRESPServer(c *Coordinator, port int) *RESPServer {
+	return &RESPServer{
+		Coordinator: c,
+		Port:        port,
+	}
+}
+
+// Start begins accepting RESP connections. It blocks until the listener
+// fails, so callers should run it in a goroutine.
+func (s *RESPServer) Start() error {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", s.Port))
+	if err != nil {
+		return fmt.Errorf("resp: failed to listen on port %d: %w", s.Port, err)
+	}
+	s.listener = ln
+
+	textLog(s.Coordinator.NodeID, "RESP", "RESP server listening on port %d", s.Port)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			textLog(s.Coordinator.NodeID, "RESP", "Accept error: %v", err)
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *RESPServer) Stop() error {
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// respConn tracks the per-connection MULTI/EXEC state.
+type respConn struct {
+	net.Conn
+	reader    *bufio.Reader
+	watchKeys map[string]map[string]int // key -> vector clock snapshot at WATCH time
+	inMulti   bool
+	queued    [][]string
+	dirtyCAS  bool
+}
+
+func (s *RESPServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	rc := &respConn{
+		Conn:      conn,
+		reader:    bufio.NewReader(conn),
+		watchKeys: make(map[string]map[string]int),
+	}
+
+	for {
+		args, err := readRESPCommand(rc.reader)
+		if err != nil {
+			if err != io.EOF {
+				textLog(s.Coordinator.NodeID, "RESP", "Command read error: %v", err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		s.dispatch(rc, args)
+	}
+}
+
+// readRESPCommand reads one client request, accepting both the RESP
+// multi-bulk array form used by real clients and bare inline commands
+// (handy for manual testing with `nc`).
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, nil
+	}
+
+	if line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("resp: malformed array header %q", line)
+	}
+
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		bulkHeader, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		bulkHeader = strings.TrimRight(bulkHeader, "\r\n")
+		if len(bulkHeader) == 0 || bulkHeader[0] != '$' {
+			return nil, fmt.Errorf("resp: expected bulk string header, got %q", bulkHeader)
+		}
+		n, err := strconv.Atoi(bulkHeader[1:])
+		if err != nil {
+			return nil, fmt.Errorf("resp: bad bulk length %q", bulkHeader)
+		}
+		buf := make([]byte, n+2) // +2 for trailing CRLF
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:n]))
+	}
+	return args, nil
+}
+
+func (s *RESPServer) dispatch(rc *respConn, args []string) {
+	cmd := strings.ToUpper(args[0])
+
+	// Queue writes while inside MULTI, except for the control commands themselves.
+	if rc.inMulti && cmd != "EXEC" && cmd != "DISCARD" && cmd != "MULTI" {
+		rc.queued = append(rc.queued, args)
+		writeSimpleString(rc, "QUEUED")
+		return
+	}
+
+	switch cmd {
+	case "PING":
+		writeSimpleString(rc, "PONG")
+	case "GET":
+		s.handleGet(rc, args)
+	case "SET":
+		s.handleSet(rc, args)
+	case "DEL":
+		s.handleDel(rc, args)
+	case "MGET":
+		s.handleMGet(rc, args)
+	case "MSET":
+		s.handleMSet(rc, args)
+	case "INFO":
+		s.handleInfo(rc)
+	case "DBSIZE":
+		s.handleDBSize(rc)
+	case "WATCH":
+		s.handleWatch(rc, args)
+	case "MULTI":
+		rc.inMulti = true
+		rc.queued = nil
+		rc.dirtyCAS = false
+		writeSimpleString(rc, "OK")
+	case "DISCARD":
+		rc.inMulti = false
+		rc.queued = nil
+		rc.watchKeys = make(map[string]map[string]int)
+		writeSimpleString(rc, "OK")
+	case "EXEC":
+		s.handleExec(rc)
+	default:
+		writeError(rc, fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+}
+
+func (s *RESPServer) handleGet(rc *respConn, args []string) {
+	if len(args) != 2 {
+		writeError(rc, "ERR wrong number of arguments for 'get' command")
+		return
+	}
+	ctx := ContextWithRequestID(context.Background(), newEventUUID())
+	result, err := s.Coordinator.Get(ctx, args[1])
+	if err != nil || result["value"] == nil || isTombstone(result["value"]) {
+		writeNullBulk(rc)
+		return
+	}
+	writeBulkString(rc, fmt.Sprintf("%v", result["value"]))
+}
+
+func (s *RESPServer) handleSet(rc *respConn, args []string) {
+	if len(args) < 3 {
+		writeError(rc, "ERR wrong number of arguments for 'set' command")
+		return
+	}
+	ctx := ContextWithRequestID(context.Background(), newEventUUID())
+	if err := s.Coordinator.Put(ctx, args[1], args[2]); err != nil {
+		writeError(rc, "ERR "+err.Error())
+		return
+	}
+	writeSimpleString(rc, "OK")
+}
+
+func (s *RESPServer) handleDel(rc *respConn, args []string) {
+	if len(args) < 2 {
+		writeError(rc, "ERR wrong number of arguments for 'del' command")
+		return
+	}
+	deleted := 0
+	for _, key := range args[1:] {
+		// A DEL is a tombstone Put: the quorum path treats it like any
+		// other write so sloppy quorum and read repair still apply.
+		ctx := ContextWithRequestID(context.Background(), newEventUUID())
+		if err := s.Coordinator.Put(ctx, key, tombstoneValue); err == nil {
+			deleted++
+		}
+	}
+	writeInteger(rc, int64(deleted))
+}
+
+func (s *RESPServer) handleMGet(rc *respConn, args []string) {
+	if len(args) < 2 {
+		writeError(rc, "ERR wrong number of arguments for 'mget' command")
+		return
+	}
+	keys := args[1:]
+	results := make([]string, len(keys))
+	found := make([]bool, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			ctx := ContextWithRequestID(context.Background(), newEventUUID())
+			result, err := s.Coordinator.Get(ctx, key)
+			if err == nil && result["value"] != nil && !isTombstone(result["value"]) {
+				results[i] = fmt.Sprintf("%v", result["value"])
+				found[i] = true
+			}
+		}(i, key)
+	}
+	wg.Wait()
+
+	fmt.Fprintf(rc, "*%d\r\n", len(keys))
+	for i := range keys {
+		if found[i] {
+			writeBulkString(rc, results[i])
+		} else {
+			writeNullBulk(rc)
+		}
+	}
+}
+
+func (s *RESPServer) handleMSet(rc *respConn, args []string) {
+	if len(args) < 3 || len(args)%2 != 1 {
+		writeError(rc, "ERR wrong number of arguments for 'mset' command")
+		return
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 0)
+	var mu sync.Mutex
+	for i := 1; i < len(args); i += 2 {
+		wg.Add(1)
+		go func(key, value string) {
+			defer wg.Done()
+			ctx := ContextWithRequestID(context.Background(), newEventUUID())
+			if err := s.Coordinator.Put(ctx, key, value); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(args[i], args[i+1])
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		writeError(rc, "ERR "+errs[0].Error())
+		return
+	}
+	writeSimpleString(rc, "OK")
+}
+
+func (s *RESPServer) handleInfo(rc *respConn) {
+	summary := s.Coordinator.Stats.GetSummary()
+	var b strings.Builder
+	b.WriteString("# Server\r\n")
+	fmt.Fprintf(&b, "node_id:%s\r\n", s.Coordinator.NodeID)
+	b.WriteString("# Keyspace\r\n")
+	ops := summary["operations"].(map[string]interface{})
+	fmt.Fprintf(&b, "get_count:%v\r\n", ops["get_count"])
+	fmt.Fprintf(&b, "put_count:%v\r\n", ops["put_count"])
+	writeBulkString(rc, b.String())
+}
+
+func (s *RESPServer) handleDBSize(rc *respConn) {
+	keyCount, _ := EstimateDataSize(s.Coordinator)
+	writeInteger(rc, keyCount)
+}
+
+func (s *RESPServer) handleWatch(rc *respConn, args []string) {
+	if len(args) < 2 {
+		writeError(rc, "ERR wrong number of arguments for 'watch' command")
+		return
+	}
+	for _, key := range args[1:] {
+		ctx := ContextWithRequestID(context.Background(), newEventUUID())
+		result, _ := s.Coordinator.Get(ctx, key)
+		vc, _ := result["vector_clock"].(map[string]int)
+		rc.watchKeys[key] = vc
+	}
+	writeSimpleString(rc, "OK")
+}
+
+// handleExec replays the queued commands, aborting (returning a nil
+// multi-bulk) if any watched key's vector clock moved since WATCH.
+func (s *RESPServer) handleExec(rc *respConn) {
+	if !rc.inMulti {
+		writeError(rc, "ERR EXEC without MULTI")
+		return
+	}
+
+	for key, snapshot := range rc.watchKeys {
+		ctx := ContextWithRequestID(context.Background(), newEventUUID())
+		result, _ := s.Coordinator.Get(ctx, key)
+		current, _ := result["vector_clock"].(map[string]int)
+		if !vectorClockMapEquals(snapshot, current) {
+			rc.dirtyCAS = true
+			break
+		}
+	}
+
+	rc.inMulti = false
+	queued := rc.queued
+	rc.queued = nil
+	rc.watchKeys = make(map[string]map[string]int)
+
+	if rc.dirtyCAS {
+		rc.dirtyCAS = false
+		writeNullArray(rc)
+		return
+	}
+
+	fmt.Fprintf(rc, "*%d\r\n", len(queued))
+	for _, cmdArgs := range queued {
+		s.dispatch(rc, cmdArgs)
+	}
+}
+
+func vectorClockMapEquals(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+const tombstoneValue = "__dynamo_tombstone__"
+
+// isTombstone reports whether v is the sentinel a "delete" txn op writes
+// in place of actually removing a key. The quorum/replication machinery
+// (vector-clock comparison, read repair, Merkle anti-entropy) needs to
+// keep seeing tombstones as real values so deletes still propagate and
+// win over stale writes; this is only for the application-facing call
+// sites - CAS predicates, client GETs, size accounting - that need to
+// treat a tombstoned key as absent.
+func isTombstone(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && s == tombstoneValue
+}
+
+func writeSimpleString(w io.Writer, s string) { fmt.Fprintf(w, "+%s\r\n", s) }
+func writeError(w io.Writer, s string)        { fmt.Fprintf(w, "-%s\r\n", s) }
+func writeInteger(w io.Writer, n int64)       { fmt.Fprintf(w, ":%d\r\n", n) }
+func writeNullBulk(w io.Writer)               { fmt.Fprint(w, "$-1\r\n") }
+func writeNullArray(w io.Writer)              { fmt.Fprint(w, "*-1\r\n") }
+func writeBulkString(w io.Writer, s string) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s)
+}
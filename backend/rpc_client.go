@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// sharedRPCTransport is reused by every internal RPC (PUT/GET/gossip/
+// repair/merkle) instead of each call site opening a fresh connection.
+// Keeping idle connections around lets repeated calls to the same peer
+// reconnect instantly instead of paying a new TCP+dial cost every time,
+// which matters once the anti-entropy and gossip loops are firing every
+// second against the same handful of nodes.
+var sharedRPCTransport = &http.Transport{
+	Proxy: nil,
+	DialContext: (&net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 10,
+	IdleConnTimeout:     90 * time.Second,
+	DisableKeepAlives:   false,
+}
+
+// newRPCClient returns an *http.Client that shares the pooled transport
+// above, scoped to a per-call timeout. Callers that used to write
+// `&http.Client{Timeout: x}` before every internal request should use
+// this instead so connections to the same peer get reused.
+func newRPCClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: sharedRPCTransport,
+	}
+}
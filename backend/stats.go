@@ -24,8 +24,11 @@ type NodeStats struct {
 	MaxPutLatency      int64 `json:"max_put_latency_ms"`
 	ConflictsDetected  int64 `json:"conflicts_detected"`
 	ConflictsResolved  int64 `json:"conflicts_resolved"`
+	CRDTMergesCount    int64 `json:"crdt_merges_count"`
 	KeyCount           int64 `json:"key_count"`
 	TotalDataSizeBytes int64 `json:"total_data_size_bytes"`
+	AntiEntropyRounds  int64 `json:"anti_entropy_rounds_count"`
+	AntiEntropyRepairs int64 `json:"anti_entropy_keys_repaired"`
 }
 
 func (s *NodeStats) UpdateStorageStats(keyCount, dataSize int64) {
@@ -78,14 +81,17 @@ func (s *NodeStats) GetSummary() map[string]interface{} {
 			"max_put_latency_ms": s.MaxPutLatency,
 		},
 		"features": map[string]interface{}{
-			"sloppy_quorum_used": s.SloppyQuorumUsed,
-			"read_repairs":       s.ReadRepairCount,
-			"hints_stored":       s.HintStoreCount,
-			"hints_delivered":    s.HintDeliverCount,
-			"conflicts_detected": s.ConflictsDetected,
-			"conflicts_resolved": s.ConflictsResolved,
-			"data_size_mb":       float64(s.TotalDataSizeBytes) / (1024 * 1024),
-			"key_count":          s.KeyCount,
+			"sloppy_quorum_used":         s.SloppyQuorumUsed,
+			"read_repairs":               s.ReadRepairCount,
+			"hints_stored":               s.HintStoreCount,
+			"hints_delivered":            s.HintDeliverCount,
+			"conflicts_detected":         s.ConflictsDetected,
+			"conflicts_resolved":         s.ConflictsResolved,
+			"crdt_merges_count":          s.CRDTMergesCount,
+			"data_size_mb":               float64(s.TotalDataSizeBytes) / (1024 * 1024),
+			"key_count":                  s.KeyCount,
+			"anti_entropy_rounds_count":  s.AntiEntropyRounds,
+			"anti_entropy_keys_repaired": s.AntiEntropyRepairs,
 		},
 	}
 }
@@ -99,6 +105,9 @@ func EstimateDataSize(c *Coordinator) (int64, int64) {
 	// In a production system, we would maintain counters or use DB stats.
 	if c.Storage != nil {
 		c.Storage.Iterate(func(k string, v storedValue) bool {
+			if isTombstone(v.Value) {
+				return true // deleted key, shouldn't count against key_count/data_size
+			}
 			keyCount++
 			totalSize += int64(len(k)) + EstimateValueSize(v)
 			return true
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// storageHandle is a refcounted wrapper around a Storage so that multiple
+// logical nodes sharing one process (and one on-disk directory, or one
+// in-memory store) can open and release it independently without either
+// colliding on file handles or closing the DB out from under a sibling.
+type storageHandle struct {
+	storage  Storage
+	refCount int
+}
+
+// StorageRegistry hands out Storage handles keyed by a URI-style
+// descriptor (badger:///path, memory://id, ...), closing the underlying
+// store only when the last holder releases it.
+type StorageRegistry struct {
+	mu      sync.Mutex
+	handles map[string]*storageHandle
+}
+
+// storageRegistry is the process-wide registry used by NewNode. Tests that
+// spin up several in-process "nodes" share it, which is what lets
+// memory:// descriptors avoid disk collisions entirely.
+var storageRegistry = &StorageRegistry{handles: make(map[string]*storageHandle)}
+
+// Get opens (or reuses) the Storage identified by uri and bumps its
+// refcount. Supported schemes today: badger://<path> and memory://<id>.
+// An empty uri falls back to badger://data/<nodeID> to preserve the
+// pre-registry default layout.
+func (r *StorageRegistry) Get(uri, nodeID string) (Storage, error) {
+	if uri == "" {
+		uri = fmt.Sprintf("badger://data/%s", nodeID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if h, exists := r.handles[uri]; exists {
+		h.refCount++
+		textLog(nodeID, "STORAGE_REGISTRY", "reusing handle for %s (refcount=%d)", uri, h.refCount)
+		return h.storage, nil
+	}
+
+	storage, err := openStorage(uri, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.handles[uri] = &storageHandle{storage: storage, refCount: 1}
+	return storage, nil
+}
+
+// Release drops a reference to the Storage identified by uri, closing the
+// underlying handle once nothing else is holding it.
+func (r *StorageRegistry) Release(uri string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, exists := r.handles[uri]
+	if !exists {
+		return fmt.Errorf("storage registry: no open handle for %s", uri)
+	}
+
+	h.refCount--
+	if h.refCount > 0 {
+		return nil
+	}
+
+	delete(r.handles, uri)
+	return h.storage.Close()
+}
+
+// OpenHandleCount reports how many distinct storage descriptors are
+// currently open, exposed as a metrics hook for callers that want to
+// alert on leaked handles.
+func (r *StorageRegistry) OpenHandleCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.handles)
+}
+
+func openStorage(uri, nodeID string) (Storage, error) {
+	switch {
+	case strings.HasPrefix(uri, "badger://"):
+		path := strings.TrimPrefix(uri, "badger://")
+		path = strings.TrimPrefix(path, "/")
+		return NewBadgerStorageAtPath(path)
+	case strings.HasPrefix(uri, "memory://"):
+		return NewMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("storage registry: unsupported URI scheme %q", uri)
+	}
+}
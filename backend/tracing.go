@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Span and Tracer stand in for go.opentelemetry.io/otel's Span/Tracer API
+// given this repo has no go.mod to pull the real SDK from. The shape
+// (Start a span with attributes, End it, read back duration) mirrors
+// OTel's API closely enough that swapping in the real SDK later is a
+// matter of replacing this file, not the call sites in node.go that use
+// it. Spans are exported by logging them through textLog rather than to
+// a collector, since there's nowhere else for them to go without network
+// exporter deps.
+type Span struct {
+	Name       string
+	NodeID     string
+	Attributes map[string]interface{}
+	start      time.Time
+	end        time.Time
+}
+
+// SetAttribute records an additional key/value on the span, the OTel
+// equivalent of span.SetAttributes(attribute.String(...)).
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]interface{})
+	}
+	s.Attributes[key] = value
+}
+
+// End closes the span and emits it as a structured log line carrying its
+// duration and attributes - this repo's stand-in for shipping the span to
+// an OTel collector.
+func (s *Span) End() {
+	s.end = time.Now()
+	textLog(s.NodeID, "TRACE", "span=%s duration_ms=%d attrs=%v",
+		s.Name, s.end.Sub(s.start).Milliseconds(), s.Attributes)
+	globalTracer.record(s)
+}
+
+// Duration returns how long the span ran; valid only after End.
+func (s *Span) Duration() time.Duration {
+	return s.end.Sub(s.start)
+}
+
+// Tracer starts spans and retains a bounded ring of the most recently
+// completed ones, the same role a real OTel TracerProvider's in-memory
+// exporter would play, so MetricsHandler (or tests) can inspect recent
+// spans without standing up a collector.
+type Tracer struct {
+	mu      sync.Mutex
+	recent  []*Span
+	maxKept int
+}
+
+// tracerRecentSpanLimit bounds Tracer's in-memory ring buffer of recently
+// completed spans.
+const tracerRecentSpanLimit = 256
+
+// globalTracer is this node's process-wide Tracer, analogous to calling
+// otel.Tracer("dynamo") once and reusing the handle.
+var globalTracer = &Tracer{maxKept: tracerRecentSpanLimit}
+
+// StartSpan begins a new span named name for nodeID, the equivalent of
+// tracer.Start(ctx, name).
+func (t *Tracer) StartSpan(nodeID, name string) *Span {
+	return &Span{Name: name, NodeID: nodeID, start: time.Now()}
+}
+
+func (t *Tracer) record(s *Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.recent = append(t.recent, s)
+	if len(t.recent) > t.maxKept {
+		t.recent = t.recent[len(t.recent)-t.maxKept:]
+	}
+}
+
+// RecentSpans returns a snapshot of the most recently completed spans,
+// newest last.
+func (t *Tracer) RecentSpans() []*Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]*Span, len(t.recent))
+	copy(out, t.recent)
+	return out
+}
+
+// String renders a span in a short human-readable form - not used by
+// End's own log line, which stays structured for grepping.
+func (s *Span) String() string {
+	return fmt.Sprintf("%s (%s) %dms", s.Name, s.NodeID, s.Duration().Milliseconds())
+}
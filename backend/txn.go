@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// TxnCompareTarget selects what part of a key's state a TxnCompare checks.
+type TxnCompareTarget string
+
+const (
+	CompareExists      TxnCompareTarget = "exists"
+	CompareAbsent      TxnCompareTarget = "absent"
+	CompareVectorClock TxnCompareTarget = "vector_clock"
+	CompareValue       TxnCompareTarget = "value"
+)
+
+// TxnCompare is a single If-branch predicate, modeled on etcd's
+// compare-and-swap clauses.
+type TxnCompare struct {
+	Key    string
+	Target TxnCompareTarget
+	Clock  map[string]int // used when Target == CompareVectorClock
+	Value  interface{}    // used when Target == CompareValue
+}
+
+// TxnOp is one action in a Txn's Then/Else branch.
+type TxnOp struct {
+	Type  string // "put", "get", "delete"
+	Key   string
+	Value interface{}
+}
+
+// TxnRequest bundles comparisons and the two op lists to run depending on
+// whether all comparisons hold.
+type TxnRequest struct {
+	Compares []TxnCompare
+	Then     []TxnOp
+	Else     []TxnOp
+}
+
+// TxnResponse reports which branch ran and what each Get op observed.
+type TxnResponse struct {
+	Succeeded bool
+	Responses []map[string]interface{}
+}
+
+// Txn evaluates Compares against the current quorum-read state of every
+// touched key, then replicates either the Then or the Else ops under a
+// single freshly-incremented vector clock. All Puts must individually
+// reach write quorum: the first Put that misses it stops the transaction
+// with an error, and Puts already replicated before it are not undone.
+func (c *Coordinator) Txn(req TxnRequest) (*TxnResponse, error) {
+	touched := c.txnTouchedKeys(req)
+
+	current := make(map[string]storedValue, len(touched))
+	for key := range touched {
+		nodes, _ := c.getResponsibleNodes(key, true)
+		responses := c.gatherResponses(nodes, key)
+		value, _ := c.resolveConflicts(responses)
+		current[key] = value
+	}
+
+	succeeded := c.evaluateCompares(req.Compares, current)
+
+	ops := req.Else
+	if succeeded {
+		ops = req.Then
+	}
+
+	vc := c.updateLocalVectorClock("txn") // single clock shared by every write in this txn
+	resp := &TxnResponse{Succeeded: succeeded}
+
+	for _, op := range ops {
+		switch op.Type {
+		case "put":
+			if err := c.txnPut(op.Key, op.Value, vc); err != nil {
+				return nil, err
+			}
+		case "delete":
+			if err := c.txnPut(op.Key, tombstoneValue, vc); err != nil {
+				return nil, err
+			}
+		case "get":
+			ctx := ContextWithRequestID(context.Background(), newEventUUID())
+			result, err := c.Get(ctx, op.Key)
+			if err != nil {
+				resp.Responses = append(resp.Responses, map[string]interface{}{"error": err.Error()})
+				continue
+			}
+			resp.Responses = append(resp.Responses, result)
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *Coordinator) txnTouchedKeys(req TxnRequest) map[string]struct{} {
+	touched := make(map[string]struct{})
+	for _, cmp := range req.Compares {
+		touched[cmp.Key] = struct{}{}
+	}
+	for _, op := range append(append([]TxnOp{}, req.Then...), req.Else...) {
+		touched[op.Key] = struct{}{}
+	}
+	return touched
+}
+
+func (c *Coordinator) evaluateCompares(compares []TxnCompare, current map[string]storedValue) bool {
+	for _, cmp := range compares {
+		sv, exists := current[cmp.Key]
+		exists = exists && sv.Value != nil && !isTombstone(sv.Value)
+
+		switch cmp.Target {
+		case CompareExists:
+			if !exists {
+				return false
+			}
+		case CompareAbsent:
+			if exists {
+				return false
+			}
+		case CompareValue:
+			if !exists {
+				return false
+			}
+			if !reflect.DeepEqual(sv.Value, cmp.Value) {
+				return false
+			}
+		case CompareVectorClock:
+			// The caller's snapshot must match exactly - anything else
+			// (the key moved on, or was concurrently written) fails the
+			// predicate, which is what gives WATCH/MULTI/EXEC its CAS semantics.
+			if !exists || !vectorClockMapEquals(sv.VectorClock.Clock, cmp.Clock) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// txnPut replicates a single key under a vector clock that is shared
+// across the whole transaction, requiring write quorum like a normal Put.
+func (c *Coordinator) txnPut(key string, value interface{}, vc *VectorClock) error {
+	nodes, replacements := c.getResponsibleNodes(key, true)
+	successNodes := c.replicateWrite(nodes, key, value, vc, ValueTypeLWW)
+	if len(successNodes) < c.WriteQuorum {
+		return errors.New("txn: insufficient replicas for write quorum on key " + key)
+	}
+	c.processSloppyReplacements(successNodes, replacements, key, value, vc, ValueTypeLWW)
+	return nil
+}